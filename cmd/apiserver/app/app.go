@@ -61,7 +61,7 @@ func NewApiServerCommand() *cobra.Command {
 			}
 
 			ctx := ctrl.SetupSignalHandler()
-			return server.Run(ctx, opts)
+			return server.Run(ctx, opts.CommonOptions)
 		},
 	}
 
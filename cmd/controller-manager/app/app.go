@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgodiscovery "k8s.io/client-go/discovery"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
 	workv1 "open-cluster-management.io/api/work/v1"
@@ -33,9 +34,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
 	"github.com/multi-cluster-platform/mcp/pkg/controllers"
 	"github.com/multi-cluster-platform/mcp/pkg/discovery"
 	controllermanageropts "github.com/multi-cluster-platform/mcp/pkg/options/controller-manager"
+	"github.com/multi-cluster-platform/mcp/pkg/webhooks"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -45,7 +48,9 @@ var (
 
 func init() {
 	// +kubebuilder:scaffold:scheme
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(appsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clusterv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(workv1.Install(scheme))
 }
 
@@ -106,6 +111,8 @@ func run(ctx context.Context, opts *controllermanageropts.Options) error {
 		LeaderElectionResourceLock: opts.LeaderElection.ResourceLock,
 		LeaderElectionNamespace:    opts.LeaderElection.ResourceNamespace,
 		LeaderElectionID:           opts.LeaderElection.ResourceName,
+		Port:                       opts.WebhookPort,
+		CertDir:                    opts.WebhookCertDir,
 	})
 	if err != nil {
 		klog.ErrorS(err, "unable to start controller-manager")
@@ -122,6 +129,79 @@ func run(ctx context.Context, opts *controllermanageropts.Options) error {
 		os.Exit(1)
 	}
 
+	if err = (&controllers.ManifestStatusController{
+		Client: mgr.GetClient(),
+		Reader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr, controller.Options{
+		MaxConcurrentReconciles: opts.ConcurrencyManifestStatus,
+	}); err != nil {
+		klog.ErrorS(err, "unable to create manifest status controller")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ClusterController{
+		Client:            mgr.GetClient(),
+		Reader:            mgr.GetAPIReader(),
+		EventRecorder:     mgr.GetEventRecorderFor("cluster-controller"),
+		HealthCheckPeriod: opts.ClusterHealthCheckPeriod,
+	}).SetupWithManager(mgr, controller.Options{
+		MaxConcurrentReconciles: opts.ConcurrencyCluster,
+	}); err != nil {
+		klog.ErrorS(err, "unable to create cluster controller")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ClusterResourceController{
+		Client:         mgr.GetClient(),
+		GatewayConfig:  mgr.GetConfig(),
+		NodePoolLabels: opts.NodePoolLabels,
+		SyncPeriod:     opts.ClusterResourceSyncPeriod,
+	}).SetupWithManager(mgr, controller.Options{
+		MaxConcurrentReconciles: opts.ConcurrencyClusterResource,
+	}); err != nil {
+		klog.ErrorS(err, "unable to create cluster resource controller")
+		os.Exit(1)
+	}
+
+	for _, resource := range opts.CaptureResources {
+		gvk, _ := controllermanageropts.ParseCaptureResource(resource)
+		if err = (&controllers.ManifestCaptureController{
+			Client: mgr.GetClient(),
+			GVK:    gvk,
+		}).SetupWithManager(mgr, controller.Options{
+			MaxConcurrentReconciles: opts.ConcurrencyManifestCapture,
+		}); err != nil {
+			klog.ErrorS(err, "unable to create manifest capture controller", "resource", resource)
+			os.Exit(1)
+		}
+	}
+
+	if opts.ManifestWorkGCPeriod > 0 {
+		if err = (&controllers.ManifestWorkGarbageCollector{
+			Client: mgr.GetClient(),
+			Reader: mgr.GetAPIReader(),
+			Period: opts.ManifestWorkGCPeriod,
+			DryRun: opts.ManifestWorkGCDryRun,
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "unable to create manifestwork garbage collector")
+			os.Exit(1)
+		}
+	}
+
+	if opts.EnableWebhook {
+		if err = (&webhooks.DeployableWebhook{
+			Reader: mgr.GetAPIReader(),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			klog.ErrorS(err, "unable to create deployable webhook")
+			os.Exit(1)
+		}
+
+		if err = (&webhooks.ManifestWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+			klog.ErrorS(err, "unable to create manifest webhook")
+			os.Exit(1)
+		}
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	klog.Info("starting controller-manager")
@@ -31,9 +31,13 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
 	"github.com/multi-cluster-platform/mcp/pkg/discovery"
 	scheduleropts "github.com/multi-cluster-platform/mcp/pkg/options/scheduler"
 	"github.com/multi-cluster-platform/mcp/pkg/scheduler"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/profile"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -44,6 +48,7 @@ var (
 func init() {
 	// +kubebuilder:scaffold:scheme
 	utilruntime.Must(appsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clusterv1alpha1.AddToScheme(scheme))
 }
 
 // NewSchedulerCommand creates a *cobra.Command object with default parameters
@@ -109,8 +114,21 @@ func run(ctx context.Context, opts *scheduleropts.Options) error {
 		os.Exit(1)
 	}
 
+	schedulerProfile, err := profile.Load(opts.ConfigFile)
+	if err != nil {
+		klog.ErrorS(err, "unable to load scheduler profile")
+		os.Exit(1)
+	}
+
+	fwk, err := framework.NewFramework(plugins.NewInTreeRegistry(), schedulerProfile, mgr.GetClient())
+	if err != nil {
+		klog.ErrorS(err, "unable to create scheduler framework")
+		os.Exit(1)
+	}
+
 	if err = (&scheduler.Scheduler{
-		Client: mgr.GetClient(),
+		Client:    mgr.GetClient(),
+		Framework: fwk,
 	}).SetupWithManager(mgr); err != nil {
 		klog.ErrorS(err, "unable to create scheduler")
 		os.Exit(1)
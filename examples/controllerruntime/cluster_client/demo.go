@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/multi-cluster-platform/mcp/pkg/wrapper"
+)
+
+func main() {
+	kubeconfig := filepath.Join(homedir.HomeDir(), ".kube", "config")
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return
+	}
+
+	// ***** The config is not wrapped, each cluster has its own client *****
+	factory := wrapper.NewClusterClientFactory(config, client.Options{Scheme: scheme.Scheme})
+
+	// list for test
+	for _, clusterName := range []string{"cluster1", "cluster2"} {
+		c, err := factory.Client(clusterName)
+		if err != nil {
+			log.Printf("error creating client of cluster %s: %v", clusterName, err)
+			continue
+		}
+		listNamespace(c, clusterName)
+		listDeployments(c, clusterName)
+	}
+}
+
+func listNamespace(c client.Client, clusterName string) {
+	nss := &corev1.NamespaceList{}
+	if err := c.List(context.TODO(), nss); err != nil {
+		log.Printf("error listing Namespaces: %v", err)
+	}
+
+	for _, ns := range nss.Items {
+		log.Printf("Cluster: %s, Namespace: %s\n", clusterName, ns.Name)
+	}
+}
+
+func listDeployments(c client.Client, clusterName string) {
+	deploys := &appsv1.DeploymentList{}
+	if err := c.List(context.TODO(), deploys); err != nil {
+		log.Printf("error listing Deployments: %v", err)
+	}
+	for _, deploy := range deploys.Items {
+		log.Printf("Cluster: %s, Deployment: %s\n", clusterName, deploy.Name)
+	}
+}
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +genclient
@@ -26,6 +27,11 @@ import (
 // +kubebuilder:resource:path=deployables,scope=Namespaced,categories=mcp-api
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Scheduled",type=string,JSONPath=`.status.conditions[?(@.type=="Scheduled")].status`
+// +kubebuilder:printcolumn:name="Applied",type=string,JSONPath=`.status.conditions[?(@.type=="Applied")].status`
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Rollout",type=string,JSONPath=`.status.rollout.phase`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // Deployable is the deploy unit for Manifests, use mcp-system as namespace for cluster scope resource
@@ -55,11 +61,161 @@ type DeployableSpec struct {
 
 	// +optional
 	Resources []corev1.ObjectReference `json:"resources,omitempty"`
+
+	// Overrides customizes the resources for the selected clusters, applied in order
+	// +optional
+	Overrides []Override `json:"overrides,omitempty"`
+
+	// DependsOn is the names of Deployables in the same namespace, the resources are applied to a cluster
+	// after the Deployables depended on are available in it
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// RolloutStrategy updates the clusters in batches when the rendered resources change,
+	// all the clusters are updated at once if not set
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+}
+
+// RolloutStrategy updates the ManifestWorks of the placed clusters progressively, the next batch is updated
+// after the workloads of updated clusters have all their replicas updated, ready and available, and the rollout
+// halts when any updated cluster is degraded or not ready within the progress deadline, until it recovers or
+// the resources change again. The clusters placed newly are not held back
+type RolloutStrategy struct {
+	// BatchSize is the number or percentage of clusters updated at a time, rounded up, defaults to 1
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	BatchSize *intstr.IntOrString `json:"batchSize,omitempty"`
+
+	// MaxUnavailable is the number or percentage of clusters allowed to be unavailable during the rollout,
+	// rounded down, defaults to BatchSize, at least 1
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// Paused stops updating more clusters, the updated ones are kept
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// ProgressDeadlineSeconds is the time for the workloads of an updated cluster to be ready before the rollout
+	// halts, defaults to 600
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
 }
 
+// Placement selects the clusters, a cluster is selected if it is listed in ClusterNames,
+// or matches both ClusterSelector and ClaimSelector when any of them is set
 type Placement struct {
 	// +optional
 	ClusterNames []string `json:"clusterNames,omitempty"`
+
+	// ClusterSelector selects the clusters by labels
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// ClaimSelector selects the clusters by cluster claims
+	// +optional
+	ClaimSelector *ClusterClaimSelector `json:"claimSelector,omitempty"`
+
+	// Tolerations tolerate the taints of clusters, the clusters with the NoSchedule taints not tolerated
+	// are filtered out unless already decided, and the ones with the NoExecute taints not tolerated are
+	// evicted after the tolerationSeconds
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// SpreadConstraints spread the selected clusters across the groups of clusters, e.g. regions and zones,
+	// all the feasible clusters are selected if not set
+	// +optional
+	SpreadConstraints []SpreadConstraint `json:"spreadConstraints,omitempty"`
+
+	// ReplicaScheduling divides the replicas of Deployments and StatefulSets across the selected clusters,
+	// each cluster runs the replicas in template if not set
+	// +optional
+	ReplicaScheduling *ReplicaScheduling `json:"replicaScheduling,omitempty"`
+}
+
+// ClusterClaimSelector is a claim query over a set of clusters
+type ClusterClaimSelector struct {
+	// matchExpressions is a list of cluster claim selector requirements, the requirements are ANDed
+	// +optional
+	MatchExpressions []metav1.LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// SpreadField is the field of Cluster grouping the clusters
+type SpreadField string
+
+const (
+	// SpreadFieldCluster makes each cluster a group of its own
+	SpreadFieldCluster SpreadField = "Cluster"
+	// SpreadFieldRegion groups the clusters by spec.region
+	SpreadFieldRegion SpreadField = "Region"
+	// SpreadFieldZone groups the clusters by spec.zone
+	SpreadFieldZone SpreadField = "Zone"
+)
+
+// SpreadConstraint limits how the selected clusters spread across the groups, the clusters without
+// the field or label are not selected. The groups are filled in turn, in the order of cluster scores
+// with the decided clusters first, so the repeated scheduling is stable
+type SpreadConstraint struct {
+	// SpreadByField groups the clusters by the field, exclusive with SpreadByLabel
+	// +kubebuilder:validation:Enum=Cluster;Region;Zone
+	// +optional
+	SpreadByField SpreadField `json:"spreadByField,omitempty"`
+
+	// SpreadByLabel groups the clusters by the value of the label, exclusive with SpreadByField
+	// +optional
+	SpreadByLabel string `json:"spreadByLabel,omitempty"`
+
+	// MinGroups is the minimum number of groups to select, the Deployable is unschedulable if fewer are feasible
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinGroups int32 `json:"minGroups,omitempty"`
+
+	// MaxGroups is the maximum number of groups to select, not limited if zero
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxGroups int32 `json:"maxGroups,omitempty"`
+
+	// MaxSkew is the maximum difference of the selected clusters between any two selected groups, not limited if zero
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxSkew int32 `json:"maxSkew,omitempty"`
+
+	// MaxClustersPerGroup is the maximum number of clusters to select in each group, not limited if zero
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxClustersPerGroup int32 `json:"maxClustersPerGroup,omitempty"`
+}
+
+// ReplicaDivisionStrategy is the strategy to divide replicas across clusters
+type ReplicaDivisionStrategy string
+
+const (
+	// ReplicaDivisionStrategyStaticWeighted divides replicas by the static weights of clusters
+	ReplicaDivisionStrategyStaticWeighted ReplicaDivisionStrategy = "StaticWeighted"
+	// ReplicaDivisionStrategyDynamicWeighted divides replicas by the available replicas of clusters
+	ReplicaDivisionStrategyDynamicWeighted ReplicaDivisionStrategy = "DynamicWeighted"
+	// ReplicaDivisionStrategyAggregated packs replicas into as few clusters as possible, the clusters without
+	// replicas are not placed
+	ReplicaDivisionStrategyAggregated ReplicaDivisionStrategy = "Aggregated"
+)
+
+type ReplicaScheduling struct {
+	// +kubebuilder:validation:Enum=StaticWeighted;DynamicWeighted;Aggregated
+	Strategy ReplicaDivisionStrategy `json:"strategy"`
+
+	// StaticWeights is used by StaticWeighted strategy, the clusters not listed get no replicas,
+	// and the replicas are divided equally if none of the placed clusters is listed
+	// +optional
+	StaticWeights []StaticClusterWeight `json:"staticWeights,omitempty"`
+}
+
+type StaticClusterWeight struct {
+	Cluster string `json:"cluster"`
+
+	// +kubebuilder:validation:Minimum=0
+	Weight int64 `json:"weight"`
 }
 
 type DeployableStatus struct {
@@ -67,19 +223,113 @@ type DeployableStatus struct {
 	// +optional
 	PlacementDecided bool `json:"placementDecided"`
 
-	// ManifestWork generated
+	// AppliedHash is the hash of ManifestWorks generated from the decisions and Manifests,
+	// ManifestWorks are regenerated when it changes or they are deleted or edited out of band
 	// +optional
-	Applied bool `json:"applied"`
+	AppliedHash string `json:"appliedHash,omitempty"`
 
 	// +optional
 	PlacementDecisions []PlacementDecision `json:"placementDecisions,omitempty"`
+
+	// ScheduledGeneration is the generation of Deployable the decisions are made for,
+	// the Deployable is rescheduled when its spec changes
+	// +optional
+	ScheduledGeneration int64 `json:"scheduledGeneration,omitempty"`
+
+	// UnschedulableReasons explains why no cluster is feasible in the last scheduling, keyed by the cluster
+	// name, an empty key means the Deployable is rejected before filtering the clusters. The placed Deployable
+	// keeps its previous decisions meanwhile
+	// +optional
+	UnschedulableReasons map[string]string `json:"unschedulableReasons,omitempty"`
+
+	// ObservedGeneration is the generation of Deployable the status is computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions is the aggregated conditions of Deployable, see DeployableCondition*
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Clusters is the rollout status in each placed cluster
+	// +optional
+	Clusters []ClusterRolloutStatus `json:"clusters,omitempty"`
+
+	// Rollout is the progress of RolloutStrategy, nil if the strategy is not set
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+}
+
+// RolloutPhase is the phase of the progressive rollout
+type RolloutPhase string
+
+const (
+	// RolloutPhaseProgressing means the clusters are being updated in batches
+	RolloutPhaseProgressing RolloutPhase = "Progressing"
+	// RolloutPhasePaused means the rollout is paused by RolloutStrategy.Paused
+	RolloutPhasePaused RolloutPhase = "Paused"
+	// RolloutPhaseHalted means the rollout is halted as some updated clusters are degraded or not ready in time
+	RolloutPhaseHalted RolloutPhase = "Halted"
+	// RolloutPhaseCompleted means all the clusters are updated
+	RolloutPhaseCompleted RolloutPhase = "Completed"
+)
+
+type RolloutStatus struct {
+	Phase RolloutPhase `json:"phase"`
+
+	// UpdatedClusters is the number of clusters whose ManifestWorks are updated to the current resources
+	UpdatedClusters int32 `json:"updatedClusters"`
+
+	// TotalClusters is the number of placed clusters
+	TotalClusters int32 `json:"totalClusters"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	// DeployableConditionScheduled means the placement decisions are made
+	DeployableConditionScheduled = "Scheduled"
+	// DeployableConditionApplied means the resources are applied in all placed clusters
+	DeployableConditionApplied = "Applied"
+	// DeployableConditionAvailable means the resources are available in all placed clusters
+	DeployableConditionAvailable = "Available"
+	// DeployableConditionDegraded means the resources are degraded in any placed cluster
+	DeployableConditionDegraded = "Degraded"
+)
+
+// ClusterRolloutStatus is the status of ManifestWork in a placed cluster
+type ClusterRolloutStatus struct {
+	Cluster string `json:"cluster"`
+
+	// Conditions is mirrored from the Applied, Available and Degraded conditions of ManifestWork
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// WaitingFor is what holds back the rest of resources in the cluster, a Deployable depended on or an
+	// apply wave not available yet, empty if all the resources are applied
+	// +optional
+	WaitingFor string `json:"waitingFor,omitempty"`
+	// RolloutHeld is true if the cluster is held back by the rollout strategy, its ManifestWork keeps the
+	// previous resources and still counts as applied and available by its conditions
+	// +optional
+	RolloutHeld bool `json:"rolloutHeld,omitempty"`
 }
 
 type PlacementDecision struct {
 	// +optional
 	Cluster string `json:"cluster,omitempty"`
 
-	// TODO, maybe add override to each resource
 	// +optional
 	Resources []corev1.ObjectReference `json:"resources,omitempty"`
+
+	// Replicas is the number of replicas divided to the cluster for each workload resource
+	// +optional
+	Replicas []ResourceReplicas `json:"replicas,omitempty"`
+}
+
+type ResourceReplicas struct {
+	Resource corev1.ObjectReference `json:"resource"`
+
+	Replicas int32 `json:"replicas"`
 }
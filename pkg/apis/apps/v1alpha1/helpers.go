@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+// ManifestName returns the name of Manifest for the resource, e.g. apps-v1-deployment-my-nginx
+func ManifestName(resource corev1.ObjectReference) string {
+	apiVersion := strings.Join(strings.Split(resource.APIVersion, "/"), "-")
+	return strings.ToLower(fmt.Sprintf("%s-%s-%s", apiVersion, resource.Kind, resource.Name))
+}
+
+// ManifestNamespace returns the namespace of Manifest for the resource, mcp-system for cluster scope resource
+func ManifestNamespace(resource corev1.ObjectReference) string {
+	if resource.Namespace == "" {
+		return constants.SystemNamespace
+	}
+	return resource.Namespace
+}
+
+// ManifestResource returns the resource described by the ManifestLabel* labels of Manifest
+func ManifestResource(manifest *Manifest) (corev1.ObjectReference, bool) {
+	labels := manifest.Labels
+	version, kind, name := labels[constants.ManifestLabelAPIVersion], labels[constants.ManifestLabelKind], labels[constants.ManifestLabelName]
+	if version == "" || kind == "" || name == "" {
+		return corev1.ObjectReference{}, false
+	}
+
+	return corev1.ObjectReference{
+		APIVersion: schema.GroupVersion{Group: labels[constants.ManifestLabelAPIGroup], Version: version}.String(),
+		Kind:       kind,
+		Namespace:  labels[constants.ManifestLabelNamespace],
+		Name:       name,
+	}, true
+}
+
+// default apply waves, the resources of a wave are applied to a cluster after the ones of earlier waves
+// are available in it
+const (
+	// ApplyWavePrerequisite is the wave of Namespaces, CustomResourceDefinitions and other cluster scope prerequisites
+	ApplyWavePrerequisite int32 = 0
+	// ApplyWaveConfig is the wave of ConfigMaps, Secrets, ServiceAccounts and the namespaced RBAC
+	ApplyWaveConfig int32 = 1
+	// ApplyWaveWorkload is the wave of the others, including workloads and custom resources
+	ApplyWaveWorkload int32 = 2
+)
+
+var defaultApplyWaves = map[schema.GroupKind]int32{
+	{Group: "", Kind: "Namespace"}:                                    ApplyWavePrerequisite,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: ApplyWavePrerequisite,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:         ApplyWavePrerequisite,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:  ApplyWavePrerequisite,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                   ApplyWavePrerequisite,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:               ApplyWavePrerequisite,
+	{Group: "", Kind: "ConfigMap"}:                                    ApplyWaveConfig,
+	{Group: "", Kind: "Secret"}:                                       ApplyWaveConfig,
+	{Group: "", Kind: "ServiceAccount"}:                               ApplyWaveConfig,
+	{Group: "", Kind: "PersistentVolumeClaim"}:                        ApplyWaveConfig,
+	{Group: "rbac.authorization.k8s.io", Kind: "Role"}:                ApplyWaveConfig,
+	{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"}:         ApplyWaveConfig,
+}
+
+// ManifestApplyWave returns the apply wave of Manifest for the resource, which is set by the
+// ManifestApplyWaveAnnotation annotation, or defaulted by the kind of resource
+func ManifestApplyWave(manifest *Manifest, resource corev1.ObjectReference) (int32, error) {
+	if value, ok := manifest.Annotations[constants.ManifestApplyWaveAnnotation]; ok {
+		wave, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid apply wave %q: %v", value, err)
+		}
+		return int32(wave), nil
+	}
+
+	gvk := schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind)
+	if wave, ok := defaultApplyWaves[gvk.GroupKind()]; ok {
+		return wave, nil
+	}
+	return ApplyWaveWorkload, nil
+}
@@ -37,10 +37,63 @@ type Manifest struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Template runtime.RawExtension `json:"template"`
 
+	// Status is the status of resource collected from the placed clusters, see ManifestStatus
+	// +kubebuilder:pruning:PreserveUnknownFields
 	// +optional
 	Status *runtime.RawExtension `json:"status,omitempty"`
 }
 
+// ManifestStatus is the status of resource aggregated from all placed clusters
+type ManifestStatus struct {
+	// TotalClusters is the number of clusters the resource is placed to
+	TotalClusters int32 `json:"totalClusters"`
+
+	// ReadyClusters is the number of clusters the resource is ready in
+	ReadyClusters int32 `json:"readyClusters"`
+
+	// Replicas is the total replicas of the workload in all placed clusters
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the total ready replicas of the workload in all placed clusters
+	// +optional
+	ReadyReplicas *int32 `json:"readyReplicas,omitempty"`
+
+	// AvailableReplicas is the total available replicas of the workload in all placed clusters
+	// +optional
+	AvailableReplicas *int32 `json:"availableReplicas,omitempty"`
+
+	// Clusters is the status of resource in each placed cluster, keyed by cluster name
+	// +optional
+	Clusters map[string]ManifestClusterStatus `json:"clusters,omitempty"`
+}
+
+// ManifestClusterStatus is the status of resource in a member cluster
+type ManifestClusterStatus struct {
+	// Ready is true if the resource is available, and all replicas are ready for the workload
+	Ready bool `json:"ready"`
+
+	// Replicas is the replicas of the workload in the cluster
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the ready replicas of the workload in the cluster
+	// +optional
+	ReadyReplicas *int32 `json:"readyReplicas,omitempty"`
+
+	// AvailableReplicas is the available replicas of the workload in the cluster
+	// +optional
+	AvailableReplicas *int32 `json:"availableReplicas,omitempty"`
+
+	// Values is the status feedback reported by the work agent
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+
+	// Conditions is the conditions of resource reported by the work agent
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Override customizes the resources placed to the selected clusters, a cluster is selected if it is
+// listed in ClusterNames, or matches ClusterSelector when it is set
+type Override struct {
+	// +optional
+	ClusterNames []string `json:"clusterNames,omitempty"`
+
+	// ClusterSelector selects the clusters by labels
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// Resources limits the override to the listed resources, all the resources of Deployable are
+	// overridden if not set
+	// +optional
+	Resources []corev1.ObjectReference `json:"resources,omitempty"`
+
+	// Overriders are applied in order: labels and annotations, images, commands and args,
+	// strategic merge patch, and JSON patches
+	Overriders Overriders `json:"overriders"`
+}
+
+type Overriders struct {
+	// Labels are added into the metadata of resource, existing keys are replaced
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added into the metadata of resource, existing keys are replaced
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ImageRegistry replaces the registry of all container images in the pod template of workloads,
+	// e.g. registry.cn-hangzhou.example.com
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// Commands replaces the commands of containers in the pod template of workloads
+	// +optional
+	Commands []ContainerCommand `json:"commands,omitempty"`
+
+	// Args replaces the args of containers in the pod template of workloads
+	// +optional
+	Args []ContainerArgs `json:"args,omitempty"`
+
+	// StrategicMergePatch is applied as strategic merge patch for built-in kinds, and as JSON merge patch for others
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	StrategicMergePatch *runtime.RawExtension `json:"strategicMergePatch,omitempty"`
+
+	// JSONPatches is applied as RFC 6902 JSON patch
+	// +optional
+	JSONPatches []JSONPatchOperation `json:"jsonPatches,omitempty"`
+}
+
+type ContainerCommand struct {
+	ContainerName string `json:"containerName"`
+
+	Command []string `json:"command"`
+}
+
+type ContainerArgs struct {
+	ContainerName string `json:"containerName"`
+
+	Args []string `json:"args"`
+}
+
+type JSONPatchOperation struct {
+	// +kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+	Op string `json:"op"`
+
+	Path string `json:"path"`
+
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// +optional
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=clusters,scope=Cluster,categories=mcp-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.spec.endpoint`
+// +kubebuilder:printcolumn:name="Region",type=string,JSONPath=`.spec.region`
+// +kubebuilder:printcolumn:name="Zone",type=string,JSONPath=`.spec.zone`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.kubernetesVersion`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Cluster is the member cluster registered to the platform, the name is used as the namespace of ManifestWork
+type Cluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterSpec `json:"spec"`
+
+	// +optional
+	Status ClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterList contains a list of Cluster
+type ClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cluster `json:"items"`
+}
+
+type ClusterSpec struct {
+	// Endpoint is the URL of the member cluster apiserver
+	Endpoint string `json:"endpoint"`
+
+	// CABundle is the PEM encoded CA bundle used to verify the member cluster apiserver
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// InsecureSkipTLSVerify skips the verification of the member cluster apiserver certificate
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// SecretRef refers to the Secret holding the credential of the member cluster,
+	// with either a token or a tls.crt and tls.key, namespace defaults to mcp-system
+	SecretRef corev1.SecretReference `json:"secretRef"`
+
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// ClusterClaims are the properties of the cluster, which could be selected by Deployables
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ClusterClaims []ClusterClaim `json:"clusterClaims,omitempty"`
+
+	// Taints repel the Deployables not tolerating them, e.g. during the maintenance window of cluster,
+	// the timeAdded of NoExecute taints is set by the controller-manager if empty
+	// +optional
+	Taints []corev1.Taint `json:"taints,omitempty"`
+}
+
+// ClusterClaim is a property of the cluster, e.g. platform=aws, kubeversion=v1.23.3
+type ClusterClaim struct {
+	Name string `json:"name"`
+
+	Value string `json:"value"`
+}
+
+// condition types
+const (
+	// ClusterConditionReady means the member cluster apiserver is healthy
+	ClusterConditionReady = "Ready"
+	// ClusterConditionUnreachable means the member cluster apiserver could not be connected
+	ClusterConditionUnreachable = "Unreachable"
+)
+
+type ClusterStatus struct {
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// KubernetesVersion is the version of the member cluster apiserver, e.g. v1.23.3
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// ProbeLatency is the latency of the last health probe to the member cluster apiserver
+	// +optional
+	ProbeLatency *metav1.Duration `json:"probeLatency,omitempty"`
+
+	// +optional
+	ResourceSummary *ResourceSummary `json:"resourceSummary,omitempty"`
+}
+
+// ResourceSummary is the summary of resources in the member cluster
+type ResourceSummary struct {
+	// Allocatable is the sum of allocatable resources of all nodes
+	// +optional
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+
+	// Allocated is the sum of resources requested by all scheduled pods, pods is the number of the pods
+	// +optional
+	Allocated corev1.ResourceList `json:"allocated,omitempty"`
+
+	// NodePools is the summary of each node pool, the pods of a workload are estimated to fit into
+	// the pools separately, as they could not span the nodes of different pools
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	NodePools []NodePoolSummary `json:"nodePools,omitempty"`
+
+	// UpdateTime is the time when the summary is collected
+	// +optional
+	UpdateTime *metav1.Time `json:"updateTime,omitempty"`
+}
+
+// NodePoolSummary is the summary of resources in a node pool, the schedulable and ready nodes
+// are grouped into pools by the node pool labels, e.g. cloud.google.com/gke-nodepool
+type NodePoolSummary struct {
+	Name string `json:"name"`
+
+	// Nodes is the number of nodes in the pool
+	Nodes int32 `json:"nodes"`
+
+	// +optional
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+
+	// +optional
+	Allocated corev1.ResourceList `json:"allocated,omitempty"`
+}
@@ -28,7 +28,7 @@ type Cluster struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	// http://localhost/apis/gateway.mcp.io/v1/clusters/{name}/api/v1/nodes
-	// Path is api/v1/nodes
+	// Path is api/v1/nodes, the name is * or names separated by comma for multiple clusters
 	// +optional
 	Path string `json:"path,omitempty"`
 }
@@ -27,7 +27,7 @@ type Shadow struct {
 	metav1.TypeMeta `json:",inline"`
 
 	// http://localhost/apis/gateway.mcp.io/v1/shadow/api/v1/nodes
-	// Path is api/v1/nodes
+	// the first segment api is taken as the name, Path is v1/nodes
 	// +optional
 	Path string `json:"path,omitempty"`
 }
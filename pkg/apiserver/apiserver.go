@@ -24,14 +24,17 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	clientgodiscovery "k8s.io/client-go/discovery"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
 	gatewayinstall "github.com/multi-cluster-platform/mcp/pkg/apis/gateway/install"
 	"github.com/multi-cluster-platform/mcp/pkg/discovery"
-	apiserveropts "github.com/multi-cluster-platform/mcp/pkg/options/apiserver"
+	"github.com/multi-cluster-platform/mcp/pkg/options/common"
 )
 
 var (
@@ -42,6 +45,9 @@ var (
 	Codecs = serializer.NewCodecFactory(Scheme)
 	// ParameterCodec handles versioning of objects that are converted to query parameters.
 	ParameterCodec = runtime.NewParameterCodec(Scheme)
+
+	// hubScheme is used by the client reading resources from the hub cluster
+	hubScheme = runtime.NewScheme()
 )
 
 func init() {
@@ -60,6 +66,9 @@ func init() {
 		&metav1.APIGroup{},
 		&metav1.APIResourceList{},
 	)
+
+	utilruntime.Must(clientgoscheme.AddToScheme(hubScheme))
+	utilruntime.Must(clusterv1alpha1.AddToScheme(hubScheme))
 }
 
 // MCPServer contains state for a Kubernetes cluster master/api server.
@@ -67,10 +76,12 @@ type MCPServer struct {
 	GenericAPIServer *genericapiserver.GenericAPIServer
 }
 
-func (server *MCPServer) Run(ctx context.Context, opts *apiserveropts.Options) error {
+// Run checks the CRDs and runs the apiserver until the context is done, it takes the common options only
+// as pkg/options/apiserver imports this package to build the Config
+func (server *MCPServer) Run(ctx context.Context, opts *common.Options) error {
 	config := ctrl.GetConfigOrDie()
 
-	if opts.CommonOptions.EnableCRDCheck {
+	if opts.EnableCRDCheck {
 		dclient, err := clientgodiscovery.NewDiscoveryClientForConfig(config)
 		if err != nil {
 			klog.ErrorS(err, "unable to new discovery client")
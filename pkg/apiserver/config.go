@@ -17,18 +17,34 @@ limitations under the License.
 package apiserver
 
 import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
 	"github.com/multi-cluster-platform/mcp/pkg/apis/gateway"
+	"github.com/multi-cluster-platform/mcp/pkg/features"
+	gatewaycache "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/cache"
 	gatewayregistry "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/cluster"
 	"github.com/multi-cluster-platform/mcp/pkg/registry/gateway/shadow"
 )
 
 // ExtraConfig holds custom apiserver config
 type ExtraConfig struct {
-	// TODO Place you custom config here.
+	// KubeConfig is used to connect to the hub cluster
+	KubeConfig *restclient.Config
+	// CachedResources is the resources of member clusters cached by the gateway
+	CachedResources []schema.GroupVersionResource
 }
 
 // Config defines the config for the apiserver
@@ -73,12 +89,51 @@ func (c completedConfig) New() (*MCPServer, error) {
 		GenericAPIServer: genericServer,
 	}
 
+	// the Clusters and their Secrets are read by every request through the gateway, so they are served from informers
+	hubCache, err := ctrlcache.New(c.ExtraConfig.KubeConfig, ctrlcache.Options{Scheme: hubScheme})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range []client.Object{&clusterv1alpha1.Cluster{}, &corev1.Secret{}} {
+		if _, err := hubCache.GetInformer(context.TODO(), obj); err != nil {
+			return nil, err
+		}
+	}
+
 	apiGroupInfo := genericapiserver.NewDefaultAPIGroupInfo(gateway.GroupName, Scheme, ParameterCodec, Codecs)
 
-	v1alpha1storage := map[string]rest.Storage{}
-	v1alpha1storage["shadow"] = shadow.NewREST()
-	v1alpha1storage["clusters"] = gatewayregistry.NewREST()
-	apiGroupInfo.VersionedResourcesStorageMap["v1alpha1"] = v1alpha1storage
+	var cache *gatewaycache.Cache
+	if utilfeature.DefaultFeatureGate.Enabled(features.ClusterCache) {
+		cache = gatewaycache.New(hubCache, c.ExtraConfig.CachedResources)
+	}
+	s.GenericAPIServer.AddPostStartHookOrDie("start-hub-cache", func(hookContext genericapiserver.PostStartHookContext) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-hookContext.StopCh
+			cancel()
+		}()
+		go func() {
+			if err := hubCache.Start(ctx); err != nil {
+				klog.ErrorS(err, "unable to start hub cache")
+			}
+		}()
+		if !hubCache.WaitForCacheSync(ctx) {
+			return fmt.Errorf("unable to sync hub cache")
+		}
+		// the member clusters are cached after the Clusters are synced
+		if cache != nil {
+			go cache.Start(ctx)
+		}
+		return nil
+	})
+
+	// the gateway API is served in v1 only, see pkg/apis/gateway/v1
+	v1storage := map[string]rest.Storage{}
+	v1storage["clusters"] = gatewayregistry.NewREST(hubCache, cache)
+	if utilfeature.DefaultFeatureGate.Enabled(features.ShadowAPI) {
+		v1storage["shadow"] = shadow.NewREST(c.ExtraConfig.KubeConfig)
+	}
+	apiGroupInfo.VersionedResourcesStorageMap["v1"] = v1storage
 
 	if err := s.GenericAPIServer.InstallAPIGroup(&apiGroupInfo); err != nil {
 		return nil, err
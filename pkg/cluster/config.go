@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+// RESTConfig builds the rest config to connect to the member cluster with the platform credential
+func RESTConfig(ctx context.Context, reader client.Reader, cluster *clusterv1alpha1.Cluster) (*restclient.Config, error) {
+	secretRef := cluster.Spec.SecretRef
+	if secretRef.Namespace == "" {
+		secretRef.Namespace = constants.SystemNamespace
+	}
+
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: secretRef.Namespace, Name: secretRef.Name}, secret); err != nil {
+		return nil, fmt.Errorf("unable to get Secret %s/%s of cluster %s: %v", secretRef.Namespace, secretRef.Name, cluster.Name, err)
+	}
+
+	config := &restclient.Config{
+		Host:        cluster.Spec.Endpoint,
+		BearerToken: string(secret.Data[corev1.ServiceAccountTokenKey]),
+		TLSClientConfig: restclient.TLSClientConfig{
+			Insecure: cluster.Spec.InsecureSkipTLSVerify,
+			CertData: secret.Data[corev1.TLSCertKey],
+			KeyData:  secret.Data[corev1.TLSPrivateKeyKey],
+		},
+	}
+	// root certificates are not allowed with the insecure flag
+	if !config.Insecure {
+		config.CAData = cluster.Spec.CABundle
+	}
+
+	if config.BearerToken == "" && (len(config.CertData) == 0 || len(config.KeyData) == 0) {
+		return nil, fmt.Errorf("no credential found in Secret %s/%s of cluster %s", secretRef.Namespace, secretRef.Name, cluster.Name)
+	}
+	return config, nil
+}
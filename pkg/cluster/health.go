@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+)
+
+// IsReady returns true if the member cluster is reported healthy by the last health probe
+func IsReady(cluster *clusterv1alpha1.Cluster) bool {
+	return meta.IsStatusConditionTrue(cluster.Status.Conditions, clusterv1alpha1.ClusterConditionReady)
+}
+
+// CheckAvailable returns a ServiceUnavailable error if the member cluster is reported unhealthy, so the requests
+// fail fast instead of waiting for the timeout, the clusters not probed yet are considered available
+func CheckAvailable(cluster *clusterv1alpha1.Cluster) error {
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, clusterv1alpha1.ClusterConditionReady)
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		return nil
+	}
+	return apierrors.NewServiceUnavailable(fmt.Sprintf("cluster %s is not ready, %s: %s", cluster.Name, condition.Reason, condition.Message))
+}
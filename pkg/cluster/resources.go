@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+)
+
+// PodRequests computes the resources requested by one pod, the same way as kube-scheduler
+func PodRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			value := requests[name]
+			value.Add(quantity)
+			requests[name] = value
+		}
+	}
+	for _, container := range spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if value, ok := requests[name]; !ok || quantity.Cmp(value) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	for name, quantity := range spec.Overhead {
+		value := requests[name]
+		value.Add(quantity)
+		requests[name] = value
+	}
+	requests[corev1.ResourcePods] = *resource.NewQuantity(1, resource.DecimalSI)
+	return requests
+}
+
+// AvailableReplicas estimates how many pods with the requests fit into the cluster, the pools are estimated
+// separately if reported, zero is returned if the cluster does not report its resource summary
+func AvailableReplicas(cluster *clusterv1alpha1.Cluster, requests corev1.ResourceList) int64 {
+	summary := cluster.Status.ResourceSummary
+	if summary == nil {
+		return 0
+	}
+	if len(summary.NodePools) == 0 {
+		return availableReplicas(summary.Allocatable, summary.Allocated, requests)
+	}
+
+	var available int64
+	for i := range summary.NodePools {
+		pool := &summary.NodePools[i]
+		available += availableReplicas(pool.Allocatable, pool.Allocated, requests)
+	}
+	return available
+}
+
+func availableReplicas(allocatable, allocated, requests corev1.ResourceList) int64 {
+	available := int64(math.MaxInt32)
+	for name, request := range requests {
+		if request.IsZero() {
+			continue
+		}
+		total, ok := allocatable[name]
+		if !ok {
+			return 0
+		}
+		free := total.DeepCopy()
+		if used, ok := allocated[name]; ok {
+			free.Sub(used)
+		}
+		if free.Sign() <= 0 {
+			return 0
+		}
+		if replicas := free.MilliValue() / request.MilliValue(); replicas < available {
+			available = replicas
+		}
+	}
+	return available
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FindUntoleratedTaint returns the first taint with one of the effects, which is not tolerated by the tolerations
+func FindUntoleratedTaint(taints []corev1.Taint, tolerations []corev1.Toleration, effects ...corev1.TaintEffect) (*corev1.Taint, bool) {
+	for i := range taints {
+		taint := &taints[i]
+		if !hasEffect(effects, taint.Effect) {
+			continue
+		}
+		if !TaintTolerated(taint, tolerations) {
+			return taint, true
+		}
+	}
+	return nil, false
+}
+
+// EvictionTime returns when the NoExecute taints evict the Deployable with the tolerations, false means never.
+// A tolerated taint evicts after the minimum tolerationSeconds of the matched tolerations counted from its
+// timeAdded, and a taint not tolerated evicts immediately.
+func EvictionTime(taints []corev1.Taint, tolerations []corev1.Toleration, now time.Time) (time.Time, bool) {
+	var evictAt time.Time
+	var evict bool
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+
+		at, ok := taintEvictionTime(taint, tolerations, now)
+		if ok && (!evict || at.Before(evictAt)) {
+			evictAt, evict = at, true
+		}
+	}
+	return evictAt, evict
+}
+
+func taintEvictionTime(taint *corev1.Taint, tolerations []corev1.Toleration, now time.Time) (time.Time, bool) {
+	var seconds *int64
+	var matched bool
+	for i := range tolerations {
+		if !tolerations[i].ToleratesTaint(taint) {
+			continue
+		}
+		matched = true
+		if s := tolerations[i].TolerationSeconds; s != nil && (seconds == nil || *s < *seconds) {
+			seconds = s
+		}
+	}
+	if !matched {
+		return now, true
+	}
+	// tolerated forever
+	if seconds == nil {
+		return time.Time{}, false
+	}
+
+	added := now
+	if taint.TimeAdded != nil {
+		added = taint.TimeAdded.Time
+	}
+	return added.Add(time.Duration(*seconds) * time.Second), true
+}
+
+// TaintTolerated returns true if the taint is tolerated by any of the tolerations
+func TaintTolerated(taint *corev1.Taint, tolerations []corev1.Toleration) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEffect(effects []corev1.TaintEffect, effect corev1.TaintEffect) bool {
+	for _, e := range effects {
+		if e == effect {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,11 @@
 package constants
 
+// namespaces
+const (
+	// SystemNamespace is the namespace for platform components and cluster scope resources
+	SystemNamespace = "mcp-system"
+)
+
 // finalizers
 const (
 	DeployableFinalizer = "deployable/apps.mcp.io"
@@ -29,3 +35,14 @@ const (
 	ManifestLabelNamespace  = "manifest.apps.mcp.io/namespace"
 	ManifestLabelName       = "manifest.apps.mcp.io/name"
 )
+
+// annotations
+const (
+	// ManifestCaptureAnnotation marks the native resource on hub to be captured into Manifest when set to "true"
+	ManifestCaptureAnnotation = "manifest.apps.mcp.io/capture"
+	// ManifestApplyWaveAnnotation sets the apply wave of Manifest, an integer overriding the default wave of its kind
+	ManifestApplyWaveAnnotation = "manifest.apps.mcp.io/apply-wave"
+	// ManifestWorkRolloutAnnotation records when the ManifestWork is updated by the rollout strategy of Deployable,
+	// and the generations of its workloads observed before the update
+	ManifestWorkRolloutAnnotation = "deployable.apps.mcp.io/rollout"
+)
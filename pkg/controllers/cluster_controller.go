@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgodiscovery "k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+)
+
+const (
+	// healthCheckTimeout is the timeout for each health check request to the member cluster
+	healthCheckTimeout = 10 * time.Second
+)
+
+// ClusterController checks the health of registered Clusters periodically
+type ClusterController struct {
+	client.Client
+	client.Reader
+	record.EventRecorder
+
+	HealthCheckPeriod time.Duration
+}
+
+var _ reconcile.Reconciler = &ClusterController{}
+
+// healthProbe is the result of a health check
+type healthProbe struct {
+	ready       metav1.Condition
+	unreachable metav1.Condition
+	version     string
+	latency     time.Duration
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (c *ClusterController) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(options).
+		Complete(c)
+}
+
+func (c *ClusterController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	klog.V(1).InfoS("reconcile for Cluster", "name", req.Name)
+
+	cluster := &clusterv1alpha1.Cluster{}
+	if err := c.Client.Get(ctx, req.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !cluster.ObjectMeta.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	probe := c.checkHealth(ctx, cluster)
+	probe.ready.ObservedGeneration = cluster.Generation
+	probe.unreachable.ObservedGeneration = cluster.Generation
+
+	runtimeObject := cluster.DeepCopy()
+	_, err := controllerutil.CreateOrPatch(ctx, c.Client, runtimeObject, func() error {
+		setTaintsTimeAdded(runtimeObject.Spec.Taints)
+		meta.SetStatusCondition(&runtimeObject.Status.Conditions, probe.ready)
+		meta.SetStatusCondition(&runtimeObject.Status.Conditions, probe.unreachable)
+		if probe.version != "" {
+			runtimeObject.Status.KubernetesVersion = probe.version
+		}
+		if probe.latency > 0 {
+			runtimeObject.Status.ProbeLatency = &metav1.Duration{Duration: probe.latency}
+		}
+		return nil
+	})
+	if err != nil {
+		klog.ErrorS(err, "unable to patch Cluster", "name", cluster.Name)
+		return reconcile.Result{}, err
+	}
+
+	c.recordTransitions(cluster, probe)
+
+	return reconcile.Result{RequeueAfter: c.HealthCheckPeriod}, nil
+}
+
+// setTaintsTimeAdded sets the timeAdded of NoExecute taints, from which the tolerationSeconds are counted
+func setTaintsTimeAdded(taints []corev1.Taint) {
+	now := metav1.Now()
+	for i := range taints {
+		if taints[i].Effect == corev1.TaintEffectNoExecute && taints[i].TimeAdded == nil {
+			taints[i].TimeAdded = &now
+		}
+	}
+}
+
+// recordTransitions emits events when the cluster becomes ready, not ready or unreachable
+func (c *ClusterController) recordTransitions(cluster *clusterv1alpha1.Cluster, probe *healthProbe) {
+	ready := meta.FindStatusCondition(cluster.Status.Conditions, clusterv1alpha1.ClusterConditionReady)
+	if ready == nil || ready.Status != probe.ready.Status {
+		if probe.ready.Status == metav1.ConditionTrue {
+			c.EventRecorder.Event(cluster, corev1.EventTypeNormal, "ClusterReady", probe.ready.Message)
+		} else {
+			c.EventRecorder.Event(cluster, corev1.EventTypeWarning, "ClusterNotReady", probe.ready.Message)
+		}
+	}
+
+	unreachable := meta.FindStatusCondition(cluster.Status.Conditions, clusterv1alpha1.ClusterConditionUnreachable)
+	if probe.unreachable.Status == metav1.ConditionTrue && (unreachable == nil || unreachable.Status != metav1.ConditionTrue) {
+		c.EventRecorder.Event(cluster, corev1.EventTypeWarning, "ClusterUnreachable", probe.unreachable.Message)
+	}
+}
+
+// checkHealth requests /readyz and /version of the member cluster apiserver with the platform credential
+func (c *ClusterController) checkHealth(ctx context.Context, cluster *clusterv1alpha1.Cluster) *healthProbe {
+	config, err := clusterutil.RESTConfig(ctx, c.Reader, cluster)
+	if err != nil {
+		return &healthProbe{
+			ready: metav1.Condition{
+				Type:    clusterv1alpha1.ClusterConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "CredentialInvalid",
+				Message: err.Error(),
+			},
+			unreachable: metav1.Condition{
+				Type:    clusterv1alpha1.ClusterConditionUnreachable,
+				Status:  metav1.ConditionUnknown,
+				Reason:  "CredentialInvalid",
+				Message: "cluster apiserver is not probed",
+			},
+		}
+	}
+	config.Timeout = healthCheckTimeout
+
+	probe := &healthProbe{}
+	dclient, err := clientgodiscovery.NewDiscoveryClientForConfig(config)
+	if err == nil {
+		start := time.Now()
+		_, err = dclient.RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+		// /readyz is not served by the apiserver older than v1.16
+		if apierrors.IsNotFound(err) {
+			start = time.Now()
+			_, err = dclient.RESTClient().Get().AbsPath("/healthz").DoRaw(ctx)
+		}
+		probe.latency = time.Since(start).Round(time.Millisecond)
+	}
+
+	// an error without status means no response is received from the apiserver
+	if _, responded := err.(apierrors.APIStatus); err != nil && !responded {
+		klog.V(1).InfoS("cluster is unreachable", "name", cluster.Name, "err", err)
+		probe.ready = metav1.Condition{
+			Type:    clusterv1alpha1.ClusterConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ClusterUnreachable",
+			Message: err.Error(),
+		}
+		probe.unreachable = metav1.Condition{
+			Type:    clusterv1alpha1.ClusterConditionUnreachable,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ClusterUnreachable",
+			Message: err.Error(),
+		}
+		// the latency of failed connections is meaningless
+		probe.latency = 0
+		return probe
+	}
+
+	probe.unreachable = metav1.Condition{
+		Type:    clusterv1alpha1.ClusterConditionUnreachable,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ClusterReachable",
+		Message: "cluster apiserver is reachable",
+	}
+
+	if version, err := dclient.ServerVersion(); err != nil {
+		klog.V(1).InfoS("unable to get version of cluster", "name", cluster.Name, "err", err)
+	} else {
+		probe.version = version.GitVersion
+	}
+
+	if err != nil {
+		klog.V(1).InfoS("cluster is unhealthy", "name", cluster.Name, "err", err)
+		probe.ready = metav1.Condition{
+			Type:    clusterv1alpha1.ClusterConditionReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ClusterUnhealthy",
+			Message: err.Error(),
+		}
+		return probe
+	}
+
+	probe.ready = metav1.Condition{
+		Type:    clusterv1alpha1.ClusterConditionReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ClusterHealthy",
+		Message: "cluster apiserver is healthy",
+	}
+	return probe
+}
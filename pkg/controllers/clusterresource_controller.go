@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	"github.com/multi-cluster-platform/mcp/pkg/wrapper"
+)
+
+const (
+	// defaultNodePool is the pool of nodes without any node pool label
+	defaultNodePool = "default"
+
+	// resourceCollectTimeout is the timeout for each list request to the member cluster
+	resourceCollectTimeout = 30 * time.Second
+)
+
+// ClusterResourceController collects the resource summary of registered Clusters periodically,
+// the nodes and pods of member clusters are listed through the gateway
+type ClusterResourceController struct {
+	client.Client
+
+	// GatewayConfig is the config of hub cluster, which serves the gateway
+	GatewayConfig *restclient.Config
+
+	// NodePoolLabels are the labels grouping nodes into pools, the first one present on the node is used
+	NodePoolLabels []string
+
+	SyncPeriod time.Duration
+}
+
+var _ reconcile.Reconciler = &ClusterResourceController{}
+
+// SetupWithManager sets up the controller with the Manager.
+func (c *ClusterResourceController) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clusterresource").
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(options).
+		Complete(c)
+}
+
+func (c *ClusterResourceController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	klog.V(1).InfoS("reconcile for Cluster resources", "name", req.Name)
+
+	cluster := &clusterv1alpha1.Cluster{}
+	if err := c.Client.Get(ctx, req.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !cluster.ObjectMeta.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+	// the last summary is kept for the cluster not ready, which is filtered out by the scheduler anyway
+	if !clusterutil.IsReady(cluster) {
+		klog.V(1).InfoS("cluster is not ready, skip collecting resources", "name", cluster.Name)
+		return reconcile.Result{RequeueAfter: c.SyncPeriod}, nil
+	}
+
+	summary, err := c.collect(ctx, cluster.Name)
+	if err != nil {
+		klog.ErrorS(err, "unable to collect resources of Cluster", "name", cluster.Name)
+		return reconcile.Result{}, err
+	}
+
+	runtimeObject := cluster.DeepCopy()
+	_, err = controllerutil.CreateOrPatch(ctx, c.Client, runtimeObject, func() error {
+		runtimeObject.Status.ResourceSummary = summary
+		return nil
+	})
+	if err != nil {
+		klog.ErrorS(err, "unable to patch Cluster", "name", cluster.Name)
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{RequeueAfter: c.SyncPeriod}, nil
+}
+
+// collect sums the allocatable resources of schedulable and ready nodes, and the requests of pods on them
+func (c *ClusterResourceController) collect(ctx context.Context, clusterName string) (*clusterv1alpha1.ResourceSummary, error) {
+	config := wrapper.ClusterConfig(c.GatewayConfig, clusterName)
+	config.Timeout = resourceCollectTimeout
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// resourceVersion=0 allows the gateway to serve the lists from its cache
+	nodeList, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		return nil, err
+	}
+	podList, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		ResourceVersion: "0",
+		FieldSelector: fields.AndSelectors(
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+			fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)),
+		).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pools := map[string]*clusterv1alpha1.NodePoolSummary{}
+	nodePools := map[string]string{}
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if node.Spec.Unschedulable || !isNodeReady(node) {
+			continue
+		}
+
+		name := c.nodePool(node)
+		pool, ok := pools[name]
+		if !ok {
+			pool = &clusterv1alpha1.NodePoolSummary{Name: name, Allocatable: corev1.ResourceList{}, Allocated: corev1.ResourceList{}}
+			pools[name] = pool
+		}
+		pool.Nodes++
+		addResourceList(pool.Allocatable, node.Status.Allocatable)
+		nodePools[node.Name] = name
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		// the pods not bound yet or on the excluded nodes take no resources of the pools
+		pool, ok := pools[nodePools[pod.Spec.NodeName]]
+		if pod.Spec.NodeName == "" || !ok {
+			continue
+		}
+		addResourceList(pool.Allocated, clusterutil.PodRequests(&pod.Spec))
+	}
+
+	now := metav1.Now()
+	summary := &clusterv1alpha1.ResourceSummary{
+		Allocatable: corev1.ResourceList{},
+		Allocated:   corev1.ResourceList{},
+		NodePools:   make([]clusterv1alpha1.NodePoolSummary, 0, len(pools)),
+		UpdateTime:  &now,
+	}
+	for _, pool := range pools {
+		addResourceList(summary.Allocatable, pool.Allocatable)
+		addResourceList(summary.Allocated, pool.Allocated)
+		summary.NodePools = append(summary.NodePools, *pool)
+	}
+	sort.Slice(summary.NodePools, func(i, j int) bool {
+		return summary.NodePools[i].Name < summary.NodePools[j].Name
+	})
+	return summary, nil
+}
+
+// nodePool returns the pool of node by the first node pool label present
+func (c *ClusterResourceController) nodePool(node *corev1.Node) string {
+	for _, label := range c.NodePoolLabels {
+		if name, ok := node.Labels[label]; ok && name != "" {
+			return name
+		}
+	}
+	return defaultNodePool
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func addResourceList(list, other corev1.ResourceList) {
+	for name, quantity := range other {
+		value := list[name]
+		value.Add(quantity)
+		list[name] = value
+	}
+}
@@ -14,11 +14,19 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// +kubebuilder:rbac:groups="",resources=events,verbs=create
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create
-// +kubebuilder:rbac:groups=apps.mcp.io,resources=manifests,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.mcp.io,resources=manifests,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=apps.mcp.io,resources=manifests/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps.mcp.io,resources=deployables,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.mcp.io,resources=deployables/status,verbs=get;create;update;patch
-// +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=users;groups;serviceaccounts,verbs=impersonate
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=userextras/*;uids,verbs=impersonate
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.mcp.io,resources=clusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cluster.mcp.io,resources=clusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=gateway.mcp.io,resources=clusters/api,verbs=get
+// +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch;create;update;delete
 
 package controllers
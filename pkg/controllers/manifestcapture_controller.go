@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+// ManifestCaptureController generates the Manifest from the native resource of GVK on hub, which is
+// annotated by constants.ManifestCaptureAnnotation, the Manifest is owned by the resource
+type ManifestCaptureController struct {
+	client.Client
+
+	GVK schema.GroupVersionKind
+}
+
+var _ reconcile.Reconciler = &ManifestCaptureController{}
+
+// SetupWithManager sets up the controller with the Manager.
+func (c *ManifestCaptureController) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(c.GVK)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("manifestcapture_"+strings.ToLower(c.GVK.Kind)+"_"+strings.ReplaceAll(c.GVK.Group, ".", "_")).
+		For(obj, builder.WithPredicates(captureChangedPredicate())).
+		Owns(&appsv1alpha1.Manifest{}).
+		WithOptions(options).
+		Complete(c)
+}
+
+// captureChangedPredicate filters the resources annotated for capture, the removal of annotation is passed
+// so the captured Manifest is deleted
+func captureChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return captureEnabled(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return captureEnabled(e.ObjectOld) || captureEnabled(e.ObjectNew)
+		},
+		// the Manifest is deleted by the garbage collector of hub with the owner
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return captureEnabled(e.Object)
+		},
+	}
+}
+
+func captureEnabled(obj client.Object) bool {
+	return obj.GetAnnotations()[constants.ManifestCaptureAnnotation] == "true"
+}
+
+func (c *ManifestCaptureController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	klog.V(1).InfoS("reconcile for Manifest capture", "kind", c.GVK.Kind, "namespace", req.Namespace, "name", req.Name)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(c.GVK)
+	if err := c.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	resource := corev1.ObjectReference{
+		APIVersion: c.GVK.GroupVersion().String(),
+		Kind:       c.GVK.Kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+	manifest := &appsv1alpha1.Manifest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: appsv1alpha1.ManifestNamespace(resource),
+			Name:      appsv1alpha1.ManifestName(resource),
+		},
+	}
+
+	if !captureEnabled(obj) {
+		return reconcile.Result{}, c.deleteManifest(ctx, obj, manifest)
+	}
+
+	template, err := captureTemplate(obj)
+	if err != nil {
+		klog.ErrorS(err, "unable to capture resource", "kind", c.GVK.Kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return reconcile.Result{}, nil
+	}
+
+	result, err := controllerutil.CreateOrUpdate(ctx, c.Client, manifest, func() error {
+		if manifest.Labels == nil {
+			manifest.Labels = make(map[string]string, 5)
+		}
+		manifest.Labels[constants.ManifestLabelAPIGroup] = c.GVK.Group
+		manifest.Labels[constants.ManifestLabelAPIVersion] = c.GVK.Version
+		manifest.Labels[constants.ManifestLabelKind] = c.GVK.Kind
+		manifest.Labels[constants.ManifestLabelNamespace] = obj.GetNamespace()
+		manifest.Labels[constants.ManifestLabelName] = obj.GetName()
+		manifest.Template = template
+		return controllerutil.SetControllerReference(obj, manifest, c.Client.Scheme())
+	})
+	if err != nil {
+		klog.ErrorS(err, "unable to create or update Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+		return reconcile.Result{}, err
+	}
+
+	if result == controllerutil.OperationResultCreated {
+		klog.V(1).InfoS("success to create Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+	} else if result == controllerutil.OperationResultUpdated {
+		klog.V(1).InfoS("success to update Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+	}
+	return reconcile.Result{}, nil
+}
+
+// deleteManifest deletes the Manifest captured from the resource, the ones written by users are kept
+func (c *ManifestCaptureController) deleteManifest(ctx context.Context, obj *unstructured.Unstructured, manifest *appsv1alpha1.Manifest) error {
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(manifest), manifest); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if owner := metav1.GetControllerOf(manifest); owner == nil || owner.UID != obj.GetUID() {
+		return nil
+	}
+
+	if err := c.Client.Delete(ctx, manifest); err != nil && !apierrors.IsNotFound(err) {
+		klog.ErrorS(err, "unable to delete Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+		return err
+	}
+	klog.V(1).InfoS("success to delete Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+	return nil
+}
+
+// captureTemplate strips the fields of resource specific to the hub, which should be set by member clusters
+func captureTemplate(obj *unstructured.Unstructured) (runtime.RawExtension, error) {
+	template := obj.DeepCopy()
+
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp",
+		"deletionGracePeriodSeconds", "selfLink", "managedFields", "ownerReferences", "finalizers"} {
+		unstructured.RemoveNestedField(template.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(template.Object, "status")
+
+	annotations := template.GetAnnotations()
+	delete(annotations, constants.ManifestCaptureAnnotation)
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	template.SetAnnotations(annotations)
+
+	gvk := template.GroupVersionKind()
+	switch {
+	case gvk.Group == corev1.GroupName && gvk.Kind == "Service":
+		// headless Service keeps the cluster ip None
+		if clusterIP, _, _ := unstructured.NestedString(template.Object, "spec", "clusterIP"); clusterIP != corev1.ClusterIPNone {
+			unstructured.RemoveNestedField(template.Object, "spec", "clusterIP")
+			unstructured.RemoveNestedField(template.Object, "spec", "clusterIPs")
+		}
+	case gvk.Group == corev1.GroupName && gvk.Kind == "PersistentVolumeClaim":
+		unstructured.RemoveNestedField(template.Object, "spec", "volumeName")
+	}
+
+	raw, err := template.MarshalJSON()
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
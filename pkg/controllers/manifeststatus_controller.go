@@ -0,0 +1,290 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+const (
+	// workloadIndexKey is the index of ManifestWorks by the Manifests of their workload
+	workloadIndexKey = "spec.workload.manifests"
+
+	// names of the status feedback of workloads
+	feedbackReplicas           = "replicas"
+	feedbackReadyReplicas      = "readyReplicas"
+	feedbackAvailableReplicas  = "availableReplicas"
+	feedbackUpdatedReplicas    = "updatedReplicas"
+	feedbackObservedGeneration = "observedGeneration"
+)
+
+// ManifestStatusController writes the status of resource in the placed clusters back into Manifest
+type ManifestStatusController struct {
+	client.Client
+	client.Reader
+}
+
+var _ reconcile.Reconciler = &ManifestStatusController{}
+
+// SetupWithManager sets up the controller with the Manager.
+func (c *ManifestStatusController) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &workv1.ManifestWork{}, workloadIndexKey, indexWorkloads); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1alpha1.Manifest{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &workv1.ManifestWork{}},
+			handler.EnqueueRequestsFromMapFunc(manifestWorkToManifests),
+		).
+		WithOptions(options).
+		Complete(c)
+}
+
+// manifestWorkToManifests maps the ManifestWork to the Manifests of its workload
+func manifestWorkToManifests(obj client.Object) []reconcile.Request {
+	keys := indexWorkloads(obj)
+
+	requests := make([]reconcile.Request, 0, len(keys))
+	for _, key := range keys {
+		namespace, name, _ := strings.Cut(key, "/")
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: namespace, Name: name}})
+	}
+	return requests
+}
+
+// indexWorkloads indexes the ManifestWork by namespace/name of the Manifests of its workload
+func indexWorkloads(obj client.Object) []string {
+	manifestWork, ok := obj.(*workv1.ManifestWork)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(manifestWork.Spec.Workload.Manifests))
+	for _, manifest := range manifestWork.Spec.Workload.Manifests {
+		resource, err := templateResource(manifest.RawExtension)
+		if err != nil {
+			klog.ErrorS(err, "unable to decode manifest of ManifestWork", "namespace", manifestWork.Namespace, "name", manifestWork.Name)
+			continue
+		}
+		keys = append(keys, appsv1alpha1.ManifestNamespace(resource)+"/"+appsv1alpha1.ManifestName(resource))
+	}
+	return keys
+}
+
+func (c *ManifestStatusController) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	klog.V(1).InfoS("reconcile for Manifest status", "namespace", req.Namespace, "name", req.Name)
+
+	manifest := &appsv1alpha1.Manifest{}
+	if err := c.Client.Get(ctx, req.NamespacedName, manifest); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	resource, ok := appsv1alpha1.ManifestResource(manifest)
+	if !ok {
+		var err error
+		if resource, err = templateResource(manifest.Template); err != nil {
+			klog.ErrorS(err, "unable to decode template of Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+			return reconcile.Result{}, nil
+		}
+		if resource.Namespace == "" && manifest.Namespace != constants.SystemNamespace {
+			resource.Namespace = manifest.Namespace
+		}
+	}
+
+	manifestWorks := &workv1.ManifestWorkList{}
+	if err := c.Client.List(ctx, manifestWorks, client.MatchingFields{workloadIndexKey: req.Namespace + "/" + req.Name}); err != nil {
+		klog.ErrorS(err, "unable to list ManifestWorks for Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+		return reconcile.Result{}, err
+	}
+
+	status := aggregateStatus(resource, manifestWorks.Items)
+	if manifest.Status != nil {
+		current := &appsv1alpha1.ManifestStatus{}
+		if err := json.Unmarshal(manifest.Status.Raw, current); err == nil && apiequality.Semantic.DeepEqual(current, status) {
+			return reconcile.Result{}, nil
+		}
+	}
+
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	runtimeObject := manifest.DeepCopy()
+	if _, err := controllerutil.CreateOrPatch(ctx, c.Client, runtimeObject, func() error {
+		runtimeObject.Status = &runtime.RawExtension{Raw: raw}
+		return nil
+	}); err != nil {
+		klog.ErrorS(err, "unable to patch status of Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// aggregateStatus collects the status of resource from the ManifestWorks, one for each placed cluster
+func aggregateStatus(resource corev1.ObjectReference, manifestWorks []workv1.ManifestWork) *appsv1alpha1.ManifestStatus {
+	gvk := schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind)
+	workload := isWorkload(gvk)
+
+	status := &appsv1alpha1.ManifestStatus{
+		TotalClusters: int32(len(manifestWorks)),
+	}
+	if workload {
+		status.Replicas, status.ReadyReplicas, status.AvailableReplicas = new(int32), new(int32), new(int32)
+	}
+	if len(manifestWorks) > 0 {
+		status.Clusters = make(map[string]appsv1alpha1.ManifestClusterStatus, len(manifestWorks))
+	}
+
+	for i := range manifestWorks {
+		// the namespace of ManifestWork is the name of registered Cluster
+		cluster := manifestWorks[i].Namespace
+		clusterStatus := appsv1alpha1.ManifestClusterStatus{}
+
+		if condition, ok := findManifestCondition(manifestWorks[i].Status.ResourceStatus.Manifests, gvk, resource); ok {
+			clusterStatus.Conditions = condition.Conditions
+			for _, value := range condition.StatusFeedbacks.Values {
+				if clusterStatus.Values == nil {
+					clusterStatus.Values = make(map[string]string, len(condition.StatusFeedbacks.Values))
+				}
+				clusterStatus.Values[value.Name] = formatFieldValue(value.Value)
+			}
+		}
+
+		clusterStatus.Ready = meta.IsStatusConditionTrue(clusterStatus.Conditions, string(workv1.ManifestAvailable))
+		if workload {
+			clusterStatus.Replicas = feedbackInteger(clusterStatus.Values, feedbackReplicas)
+			clusterStatus.ReadyReplicas = feedbackInteger(clusterStatus.Values, feedbackReadyReplicas)
+			clusterStatus.AvailableReplicas = feedbackInteger(clusterStatus.Values, feedbackAvailableReplicas)
+
+			*status.Replicas += int32Value(clusterStatus.Replicas)
+			*status.ReadyReplicas += int32Value(clusterStatus.ReadyReplicas)
+			*status.AvailableReplicas += int32Value(clusterStatus.AvailableReplicas)
+
+			// the workload is ready only if the feedback is reported and all the replicas are ready
+			clusterStatus.Ready = clusterStatus.Ready && clusterStatus.Replicas != nil &&
+				int32Value(clusterStatus.ReadyReplicas) >= *clusterStatus.Replicas
+		}
+
+		if clusterStatus.Ready {
+			status.ReadyClusters++
+		}
+		status.Clusters[cluster] = clusterStatus
+	}
+
+	return status
+}
+
+// findManifestCondition returns the condition reported by the work agent for the resource
+func findManifestCondition(conditions []workv1.ManifestCondition, gvk schema.GroupVersionKind, resource corev1.ObjectReference) (workv1.ManifestCondition, bool) {
+	for _, condition := range conditions {
+		resourceMeta := condition.ResourceMeta
+		if resourceMeta.Group == gvk.Group && resourceMeta.Kind == gvk.Kind && resourceMeta.Namespace == resource.Namespace && resourceMeta.Name == resource.Name {
+			return condition, true
+		}
+	}
+	return workv1.ManifestCondition{}, false
+}
+
+// templateResource returns the resource described by the raw template
+func templateResource(template runtime.RawExtension) (corev1.ObjectReference, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(template.Raw); err != nil {
+		return corev1.ObjectReference{}, err
+	}
+	return corev1.ObjectReference{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}, nil
+}
+
+// isWorkload returns true if replicas of the kind are reported, see ManifestWorkController.manifestConfig
+func isWorkload(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == appsv1.GroupName && (gvk.Kind == "Deployment" || gvk.Kind == "StatefulSet" || gvk.Kind == "ReplicaSet")
+}
+
+func formatFieldValue(value workv1.FieldValue) string {
+	switch value.Type {
+	case workv1.Integer:
+		if value.Integer != nil {
+			return strconv.FormatInt(*value.Integer, 10)
+		}
+	case workv1.String:
+		if value.String != nil {
+			return *value.String
+		}
+	case workv1.Boolean:
+		if value.Boolean != nil {
+			return strconv.FormatBool(*value.Boolean)
+		}
+	}
+	return ""
+}
+
+func feedbackInteger(values map[string]string, name string) *int32 {
+	value, ok := values[name]
+	if !ok {
+		return nil
+	}
+	i, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil
+	}
+	result := int32(i)
+	return &result
+}
+
+func int32Value(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
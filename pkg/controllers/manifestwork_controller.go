@@ -18,22 +18,48 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	workv1 "open-cluster-management.io/api/work/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
 	"github.com/multi-cluster-platform/mcp/pkg/constants"
+	"github.com/multi-cluster-platform/mcp/pkg/overrides"
+)
+
+const (
+	// manifestIndexKey is the index of Deployables by the Manifests they refer to
+	manifestIndexKey = "spec.resources.manifest"
+	// manifestWorkIndexKey is the index of Deployables by the ManifestWorks of their decisions
+	manifestWorkIndexKey = "status.placementDecisions.manifestWork"
+	// dependsOnIndexKey is the index of Deployables by the Deployables they depend on
+	dependsOnIndexKey = "spec.dependsOn"
 )
 
 type ManifestWorkController struct {
@@ -45,12 +71,171 @@ var _ reconcile.Reconciler = &ManifestWorkController{}
 
 // SetupWithManager sets up the controller with the Manager.
 func (c *ManifestWorkController) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &appsv1alpha1.Deployable{}, manifestIndexKey, indexManifests); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &appsv1alpha1.Deployable{}, manifestWorkIndexKey, indexManifestWorks); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &appsv1alpha1.Deployable{}, dependsOnIndexKey, indexDependsOn); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appsv1alpha1.Deployable{}).
+		Watches(
+			&source.Kind{Type: &appsv1alpha1.Manifest{}},
+			handler.EnqueueRequestsFromMapFunc(c.manifestToDeployables),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Watches(
+			&source.Kind{Type: &workv1.ManifestWork{}},
+			handler.EnqueueRequestsFromMapFunc(c.manifestWorkToDeployables),
+		).
+		Watches(
+			&source.Kind{Type: &appsv1alpha1.Deployable{}},
+			handler.EnqueueRequestsFromMapFunc(c.deployableToDependents),
+		).
+		Watches(
+			&source.Kind{Type: &clusterv1alpha1.Cluster{}},
+			handler.EnqueueRequestsFromMapFunc(c.clusterToDeployables),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
 		WithOptions(options).
 		Complete(c)
 }
 
+// manifestToDeployables maps the Manifest to the Deployables referring to its resource
+func (c *ManifestWorkController) manifestToDeployables(obj client.Object) []reconcile.Request {
+	manifest, ok := obj.(*appsv1alpha1.Manifest)
+	if !ok {
+		return nil
+	}
+
+	name := manifest.Name
+	if resource, ok := appsv1alpha1.ManifestResource(manifest); ok {
+		name = appsv1alpha1.ManifestName(resource)
+	}
+
+	deployables := &appsv1alpha1.DeployableList{}
+	if err := c.Client.List(context.TODO(), deployables, client.MatchingFields{manifestIndexKey: manifest.Namespace + "/" + name}); err != nil {
+		klog.ErrorS(err, "unable to list Deployables for Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(deployables.Items))
+	for i := range deployables.Items {
+		requests[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&deployables.Items[i])}
+	}
+	return requests
+}
+
+// clusterToDeployables maps the Cluster to the Deployables placed to it and overriding by cluster labels,
+// so the overrides are rendered again when the labels change
+func (c *ManifestWorkController) clusterToDeployables(obj client.Object) []reconcile.Request {
+	deployables := &appsv1alpha1.DeployableList{}
+	if err := c.Client.List(context.TODO(), deployables); err != nil {
+		klog.ErrorS(err, "unable to list Deployables for Cluster", "name", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range deployables.Items {
+		deployable := &deployables.Items[i]
+		if !overridesByClusterSelector(deployable) {
+			continue
+		}
+		for _, decision := range deployable.Status.PlacementDecisions {
+			if decision.Cluster == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(deployable)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+func overridesByClusterSelector(deployable *appsv1alpha1.Deployable) bool {
+	for _, override := range deployable.Spec.Overrides {
+		if override.ClusterSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestWorkToDeployables maps the ManifestWork to the Deployables it is generated for
+func (c *ManifestWorkController) manifestWorkToDeployables(obj client.Object) []reconcile.Request {
+	deployables := &appsv1alpha1.DeployableList{}
+	if err := c.Client.List(context.TODO(), deployables, client.MatchingFields{manifestWorkIndexKey: obj.GetNamespace() + "/" + obj.GetName()}); err != nil {
+		klog.ErrorS(err, "unable to list Deployables for ManifestWork", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(deployables.Items))
+	for i := range deployables.Items {
+		requests[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&deployables.Items[i])}
+	}
+	return requests
+}
+
+// deployableToDependents maps the Deployable to the ones depending on it
+func (c *ManifestWorkController) deployableToDependents(obj client.Object) []reconcile.Request {
+	deployables := &appsv1alpha1.DeployableList{}
+	if err := c.Client.List(context.TODO(), deployables, client.MatchingFields{dependsOnIndexKey: obj.GetNamespace() + "/" + obj.GetName()}); err != nil {
+		klog.ErrorS(err, "unable to list Deployables depending on Deployable", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(deployables.Items))
+	for i := range deployables.Items {
+		requests[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&deployables.Items[i])}
+	}
+	return requests
+}
+
+// indexDependsOn indexes the Deployable by namespace/name of the Deployables it depends on
+func indexDependsOn(obj client.Object) []string {
+	deployable, ok := obj.(*appsv1alpha1.Deployable)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, len(deployable.Spec.DependsOn))
+	for i, name := range deployable.Spec.DependsOn {
+		keys[i] = deployable.Namespace + "/" + name
+	}
+	return keys
+}
+
+// indexManifestWorks indexes the Deployable by namespace/name of the ManifestWorks of its decisions
+func indexManifestWorks(obj client.Object) []string {
+	deployable, ok := obj.(*appsv1alpha1.Deployable)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, len(deployable.Status.PlacementDecisions))
+	for i, decision := range deployable.Status.PlacementDecisions {
+		keys[i] = decision.Cluster + "/" + manifestWorkName(deployable)
+	}
+	return keys
+}
+
+// indexManifests indexes the Deployable by namespace/name of the Manifests it refers to
+func indexManifests(obj client.Object) []string {
+	deployable, ok := obj.(*appsv1alpha1.Deployable)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, len(deployable.Spec.Resources))
+	for i, resource := range deployable.Spec.Resources {
+		keys[i] = appsv1alpha1.ManifestNamespace(resource) + "/" + appsv1alpha1.ManifestName(resource)
+	}
+	return keys
+}
+
 func (c *ManifestWorkController) Reconcile(ctx context.Context, req reconcile.Request) (_ reconcile.Result, reterr error) {
 	klog.V(1).InfoS("reconcile for Deployable", "namespace", req.Namespace, "name", req.Name)
 
@@ -66,7 +251,11 @@ func (c *ManifestWorkController) Reconcile(ctx context.Context, req reconcile.Re
 		runtimeObject := deployable.DeepCopy()
 		_, err := controllerutil.CreateOrPatch(ctx, c.Client, runtimeObject, func() error {
 			runtimeObject.ObjectMeta.Finalizers = deployable.ObjectMeta.Finalizers
-			runtimeObject.Status.Applied = deployable.Status.Applied
+			runtimeObject.Status.AppliedHash = deployable.Status.AppliedHash
+			runtimeObject.Status.ObservedGeneration = deployable.Status.ObservedGeneration
+			runtimeObject.Status.Conditions = deployable.Status.Conditions
+			runtimeObject.Status.Clusters = deployable.Status.Clusters
+			runtimeObject.Status.Rollout = deployable.Status.Rollout
 			return nil
 		})
 		if err != nil {
@@ -92,74 +281,141 @@ func (c *ManifestWorkController) reconcileDelete(ctx context.Context, deployable
 	klog.V(1).InfoS("reconcile for Deployable delete", "namespace", deployable.Namespace, "name", deployable.Name)
 
 	if deployable.Status.PlacementDecided {
-		// delete decided resources
+		// delete decided resources, and the ones of dropped clusters which may be not deleted yet
+		clusters := sets.NewString()
 		for _, decision := range deployable.Status.PlacementDecisions {
-			manifestWork := &workv1.ManifestWork{
-				ObjectMeta: metav1.ObjectMeta{
-					Namespace: decision.Cluster,
-					Name:      fmt.Sprintf("%s-%s", deployable.Namespace, deployable.Name),
-				},
-			}
-			if err := c.Client.Delete(ctx, manifestWork); err != nil {
-				if apierrors.IsNotFound(err) {
-					continue
-				}
+			clusters.Insert(decision.Cluster)
+		}
+		for _, status := range deployable.Status.Clusters {
+			clusters.Insert(status.Cluster)
+		}
+		for _, cluster := range clusters.List() {
+			if err := c.deleteManifestWork(ctx, deployable, cluster); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
 	}
 
-	deployable.Status.Applied = false
+	deployable.Status.AppliedHash = ""
 	controllerutil.RemoveFinalizer(deployable, constants.DeployableFinalizer)
 	return reconcile.Result{}, nil
 }
 
+// formatUnschedulableReasons joins the reasons ordered by cluster, the reason without cluster goes first
+func formatUnschedulableReasons(reasons map[string]string) string {
+	clusters := make([]string, 0, len(reasons))
+	for cluster := range reasons {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	messages := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster == "" {
+			messages = append(messages, reasons[cluster])
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", cluster, reasons[cluster]))
+	}
+	return strings.Join(messages, "; ")
+}
+
 func (c *ManifestWorkController) reconcileNormal(ctx context.Context, deployable *appsv1alpha1.Deployable) (reconcile.Result, error) {
 	klog.V(1).InfoS("reconcile for Deployable normal", "namespace", deployable.Namespace, "name", deployable.Name)
 
 	if !deployable.Status.PlacementDecided {
 		klog.V(1).InfoS("deployable is not scheduled, skip", "namespace", deployable.Namespace, "name", deployable.Name)
+		if len(deployable.Status.UnschedulableReasons) > 0 {
+			setCondition(deployable, appsv1alpha1.DeployableConditionScheduled, metav1.ConditionFalse, "Unschedulable",
+				formatUnschedulableReasons(deployable.Status.UnschedulableReasons))
+		} else {
+			setCondition(deployable, appsv1alpha1.DeployableConditionScheduled, metav1.ConditionFalse, "Pending", "waiting for the scheduler to make placement decisions")
+		}
 		return reconcile.Result{}, nil
 	}
-
-	if deployable.Status.Applied {
-		klog.V(1).InfoS("deployable is already applied, skip", "namespace", deployable.Namespace, "name", deployable.Name)
-		return reconcile.Result{}, nil
+	if len(deployable.Status.UnschedulableReasons) > 0 {
+		// no cluster fits the current spec, so nothing is placed
+		setCondition(deployable, appsv1alpha1.DeployableConditionScheduled, metav1.ConditionFalse, "Unschedulable",
+			formatUnschedulableReasons(deployable.Status.UnschedulableReasons))
+	} else if deployable.Status.ScheduledGeneration != deployable.Generation {
+		// the previous decisions are applied until the scheduler catches up with the spec
+		setCondition(deployable, appsv1alpha1.DeployableConditionScheduled, metav1.ConditionFalse, "Rescheduling", "waiting for the scheduler to reschedule")
+	} else {
+		setCondition(deployable, appsv1alpha1.DeployableConditionScheduled, metav1.ConditionTrue, "Scheduled",
+			fmt.Sprintf("placed to %d clusters", len(deployable.Status.PlacementDecisions)))
 	}
 
+	manifestWorks := make([]*workv1.ManifestWork, 0, len(deployable.Status.PlacementDecisions))
+	existingWorks := make(map[string]*workv1.ManifestWork, len(deployable.Status.PlacementDecisions))
+	waitingFor := make(map[string]string)
 	for _, decision := range deployable.Status.PlacementDecisions {
-		// handle for each ManifestWork
-		manifestWork := &workv1.ManifestWork{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: decision.Cluster,
-				Name:      fmt.Sprintf("%s-%s", deployable.Namespace, deployable.Name),
-			},
-			Spec: workv1.ManifestWorkSpec{
-				Workload: workv1.ManifestsTemplate{
-					Manifests: make([]workv1.Manifest, len(decision.Resources)),
-				},
-			},
+		// the namespace of ManifestWork is the name of registered Cluster
+		cluster := &clusterv1alpha1.Cluster{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Name: decision.Cluster}, cluster); err != nil {
+			klog.ErrorS(err, "unable to get Cluster", "name", decision.Cluster)
+			return reconcile.Result{}, err
 		}
 
-		for idx, resource := range decision.Resources {
-			manifest := &appsv1alpha1.Manifest{
-				ObjectMeta: metav1.ObjectMeta{
-					Namespace: resource.Namespace,
-					Name:      strings.ToLower(fmt.Sprintf("%s-%s-%s", convertAPIVersion(resource.APIVersion), resource.Kind, resource.Name)),
-				},
-			}
-			if err := c.Client.Get(ctx, client.ObjectKeyFromObject(manifest), manifest); err != nil {
-				klog.ErrorS(err, "unable to get Manifest", "namespace", manifestWork.Namespace, "name", manifestWork.Name)
+		// the ManifestWork applied before is kept as it is until the dependencies are available again
+		dependency, err := c.waitForDependencies(ctx, deployable, decision.Cluster)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if dependency != "" {
+			klog.V(1).InfoS("deployable is waiting for dependency", "namespace", deployable.Namespace, "name", deployable.Name, "cluster", decision.Cluster, "dependency", dependency)
+			waitingFor[decision.Cluster] = dependency
+			continue
+		}
+
+		existing := &workv1.ManifestWork{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Namespace: decision.Cluster, Name: manifestWorkName(deployable)}, existing); err != nil {
+			if !apierrors.IsNotFound(err) {
 				return reconcile.Result{}, err
 			}
+			existing = nil
+		} else {
+			existingWorks[decision.Cluster] = existing
+		}
 
-			manifestWork.Spec.Workload.Manifests[idx] = workv1.Manifest{
-				RawExtension: manifest.Template,
-			}
+		manifestWork, wave, err := c.generateManifestWork(ctx, deployable, decision, cluster, existing)
+		if err != nil {
+			return reconcile.Result{}, err
 		}
+		if wave != "" {
+			waitingFor[decision.Cluster] = wave
+		}
+		manifestWorks = append(manifestWorks, manifestWork)
+	}
+
+	hash, err := computeHash(manifestWorks)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
+	// the hash is recorded after all the ManifestWorks are rolled out, so the rollout goes on until then
+	held := sets.NewString()
+	manifestWorks, deployable.Status.Rollout = planRollout(deployable, manifestWorks, existingWorks, held, time.Now())
+	// the ManifestWorks deleted or edited out of band are applied again though the hash is not changed
+	if hash == deployable.Status.AppliedHash && manifestWorksApplied(manifestWorks, existingWorks) {
+		klog.V(1).InfoS("deployable is already applied, skip", "namespace", deployable.Namespace, "name", deployable.Name)
+		return c.updateRolloutStatus(ctx, deployable, waitingFor, held)
+	}
+
+	for _, manifestWork := range manifestWorks {
 		runtimeObject := manifestWork.DeepCopy()
 		result, err := controllerutil.CreateOrUpdate(ctx, c.Client, runtimeObject, func() error {
+			if runtimeObject.Labels == nil {
+				runtimeObject.Labels = make(map[string]string, len(manifestWork.Labels))
+			}
+			for k, v := range manifestWork.Labels {
+				runtimeObject.Labels[k] = v
+			}
+			if len(manifestWork.Annotations) > 0 && runtimeObject.Annotations == nil {
+				runtimeObject.Annotations = make(map[string]string, len(manifestWork.Annotations))
+			}
+			for k, v := range manifestWork.Annotations {
+				runtimeObject.Annotations[k] = v
+			}
 			runtimeObject.Spec = manifestWork.Spec
 			return nil
 		})
@@ -175,11 +431,425 @@ func (c *ManifestWorkController) reconcileNormal(ctx context.Context, deployable
 		}
 	}
 
-	deployable.Status.Applied = true
+	// the clusters with rollout status are the ones applied before, delete the ManifestWorks of dropped ones
+	decided := sets.NewString()
+	for _, decision := range deployable.Status.PlacementDecisions {
+		decided.Insert(decision.Cluster)
+	}
+	for _, status := range deployable.Status.Clusters {
+		if decided.Has(status.Cluster) {
+			continue
+		}
+		if err := c.deleteManifestWork(ctx, deployable, status.Cluster); err != nil {
+			return reconcile.Result{}, err
+		}
+		klog.V(1).InfoS("success to delete ManifestWork of dropped cluster", "namespace", deployable.Namespace, "name", deployable.Name, "cluster", status.Cluster)
+	}
+
+	if rollout := deployable.Status.Rollout; rollout == nil || rollout.Phase == appsv1alpha1.RolloutPhaseCompleted {
+		deployable.Status.AppliedHash = hash
+	}
+
+	return c.updateRolloutStatus(ctx, deployable, waitingFor, held)
+}
+
+// manifestWorksApplied returns true if the generated ManifestWorks all exist and are not changed, see manifestWorkUpdated
+func manifestWorksApplied(manifestWorks []*workv1.ManifestWork, existing map[string]*workv1.ManifestWork) bool {
+	for _, manifestWork := range manifestWorks {
+		current, ok := existing[manifestWork.Namespace]
+		if !ok || !manifestWorkUpdated(current, manifestWork) {
+			return false
+		}
+	}
+	return true
+}
+
+// waitForDependencies returns the first Deployable depended on which is not available in the cluster yet,
+// or the dependency cycle the Deployable is in, which never becomes available
+func (c *ManifestWorkController) waitForDependencies(ctx context.Context, deployable *appsv1alpha1.Deployable, cluster string) (string, error) {
+	cycle, err := c.dependencyCycle(ctx, deployable, []string{deployable.Name}, sets.NewString())
+	if err != nil {
+		return "", err
+	}
+	if len(cycle) > 0 {
+		return fmt.Sprintf("dependency cycle %s", strings.Join(cycle, " -> ")), nil
+	}
+
+	for _, name := range deployable.Spec.DependsOn {
+		dependency := &appsv1alpha1.Deployable{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Namespace: deployable.Namespace, Name: name}, dependency); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("Deployable %s", name), nil
+			}
+			klog.ErrorS(err, "unable to get Deployable", "namespace", deployable.Namespace, "name", name)
+			return "", err
+		}
+		if !availableInCluster(dependency, cluster) {
+			return fmt.Sprintf("Deployable %s", name), nil
+		}
+	}
+	return "", nil
+}
+
+// dependencyCycle returns the path of Deployables depending on each other back to the first one in the path,
+// the Deployables visited without reaching it are skipped
+func (c *ManifestWorkController) dependencyCycle(ctx context.Context, deployable *appsv1alpha1.Deployable, path []string, visited sets.String) ([]string, error) {
+	for _, name := range deployable.Spec.DependsOn {
+		if name == path[0] {
+			return append(path, name), nil
+		}
+		if visited.Has(name) {
+			continue
+		}
+		visited.Insert(name)
+
+		dependency := &appsv1alpha1.Deployable{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Namespace: deployable.Namespace, Name: name}, dependency); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			klog.ErrorS(err, "unable to get Deployable", "namespace", deployable.Namespace, "name", name)
+			return nil, err
+		}
+		cycle, err := c.dependencyCycle(ctx, dependency, append(path, name), visited)
+		if err != nil || len(cycle) > 0 {
+			return cycle, err
+		}
+	}
+	return nil, nil
+}
+
+// availableInCluster returns true if all the resources of Deployable are applied and available in the cluster
+func availableInCluster(deployable *appsv1alpha1.Deployable, cluster string) bool {
+	for _, status := range deployable.Status.Clusters {
+		if status.Cluster == cluster {
+			return status.WaitingFor == "" && meta.IsStatusConditionTrue(status.Conditions, workv1.WorkAvailable)
+		}
+	}
+	return false
+}
+
+// deleteManifestWork deletes the ManifestWork of Deployable in the cluster namespace
+func (c *ManifestWorkController) deleteManifestWork(ctx context.Context, deployable *appsv1alpha1.Deployable, cluster string) error {
+	manifestWork := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster,
+			Name:      manifestWorkName(deployable),
+		},
+	}
+	if err := c.Client.Delete(ctx, manifestWork); err != nil && !apierrors.IsNotFound(err) {
+		klog.ErrorS(err, "unable to delete ManifestWork", "namespace", manifestWork.Namespace, "name", manifestWork.Name)
+		return err
+	}
+	return nil
+}
+
+// updateRolloutStatus mirrors the conditions of ManifestWorks into the Deployable and aggregates them,
+// the clusters waiting for dependencies or apply waves are neither applied nor available, while the ones held back by
+// the rollout count by the ManifestWorks applied before
+func (c *ManifestWorkController) updateRolloutStatus(ctx context.Context, deployable *appsv1alpha1.Deployable, waitingFor map[string]string, held sets.String) (reconcile.Result, error) {
+	var applied, available int
+	var degraded []string
+
+	clusters := make([]appsv1alpha1.ClusterRolloutStatus, 0, len(deployable.Status.PlacementDecisions))
+	for _, decision := range deployable.Status.PlacementDecisions {
+		status := appsv1alpha1.ClusterRolloutStatus{
+			Cluster:     decision.Cluster,
+			WaitingFor:  waitingFor[decision.Cluster],
+			RolloutHeld: held.Has(decision.Cluster),
+		}
+
+		manifestWork := &workv1.ManifestWork{}
+		if err := c.Client.Get(ctx, client.ObjectKey{Namespace: decision.Cluster, Name: manifestWorkName(deployable)}, manifestWork); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+			// the ManifestWork just created may not be in the cache yet, it is watched
+		}
+
+		for _, conditionType := range []string{workv1.WorkApplied, workv1.WorkAvailable, workv1.WorkDegraded} {
+			if condition := meta.FindStatusCondition(manifestWork.Status.Conditions, conditionType); condition != nil {
+				status.Conditions = append(status.Conditions, *condition)
+			}
+		}
+		if meta.IsStatusConditionTrue(status.Conditions, workv1.WorkApplied) && status.WaitingFor == "" {
+			applied++
+		}
+		if meta.IsStatusConditionTrue(status.Conditions, workv1.WorkAvailable) && status.WaitingFor == "" {
+			available++
+		}
+		if meta.IsStatusConditionTrue(status.Conditions, workv1.WorkDegraded) {
+			degraded = append(degraded, decision.Cluster)
+		}
+
+		clusters = append(clusters, status)
+	}
+	deployable.Status.Clusters = clusters
+
+	total := len(clusters)
+	if total == 0 {
+		setCondition(deployable, appsv1alpha1.DeployableConditionApplied, metav1.ConditionFalse, "NoCluster", "no cluster is placed")
+		setCondition(deployable, appsv1alpha1.DeployableConditionAvailable, metav1.ConditionFalse, "NoCluster", "no cluster is placed")
+	} else {
+		if applied == total {
+			setCondition(deployable, appsv1alpha1.DeployableConditionApplied, metav1.ConditionTrue, "Applied", fmt.Sprintf("%d/%d clusters applied", applied, total))
+		} else {
+			setCondition(deployable, appsv1alpha1.DeployableConditionApplied, metav1.ConditionFalse, "Applying", fmt.Sprintf("%d/%d clusters applied", applied, total))
+		}
+		if available == total {
+			setCondition(deployable, appsv1alpha1.DeployableConditionAvailable, metav1.ConditionTrue, "Available", fmt.Sprintf("%d/%d clusters available", available, total))
+		} else {
+			setCondition(deployable, appsv1alpha1.DeployableConditionAvailable, metav1.ConditionFalse, "Unavailable", fmt.Sprintf("%d/%d clusters available", available, total))
+		}
+	}
+	if len(degraded) > 0 {
+		setCondition(deployable, appsv1alpha1.DeployableConditionDegraded, metav1.ConditionTrue, "Degraded",
+			fmt.Sprintf("degraded in clusters: %s", strings.Join(degraded, ", ")))
+	} else {
+		setCondition(deployable, appsv1alpha1.DeployableConditionDegraded, metav1.ConditionFalse, "NotDegraded", "")
+	}
 
+	deployable.Status.ObservedGeneration = deployable.Generation
+	// the progress deadline passes without any event of ManifestWorks, so the rollout in progress is checked again
+	if rollout := deployable.Status.Rollout; rollout != nil && rollout.Phase == appsv1alpha1.RolloutPhaseProgressing {
+		return reconcile.Result{RequeueAfter: rolloutCheckPeriod}, nil
+	}
 	return reconcile.Result{}, nil
 }
 
-func convertAPIVersion(apiVersion string) string {
-	return strings.Join(strings.Split(apiVersion, "/"), "-")
+// setCondition sets the condition of Deployable with the current generation
+func setCondition(deployable *appsv1alpha1.Deployable, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&deployable.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: deployable.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// manifestWorkName returns the name of ManifestWork generated for the Deployable in the cluster namespace
+func manifestWorkName(deployable *appsv1alpha1.Deployable) string {
+	return fmt.Sprintf("%s-%s", deployable.Namespace, deployable.Name)
+}
+
+// generateManifestWork renders the Manifests of the decision into the ManifestWork of the cluster,
+// with the divided replicas and the overrides selecting the cluster. The resources are ordered by their
+// apply waves, and a wave is added after the ones of earlier waves are available in the existing ManifestWork,
+// the apply wave waited for is returned if any. The resources in the existing ManifestWork are always kept
+func (c *ManifestWorkController) generateManifestWork(ctx context.Context, deployable *appsv1alpha1.Deployable, decision appsv1alpha1.PlacementDecision,
+	cluster *clusterv1alpha1.Cluster, existing *workv1.ManifestWork) (*workv1.ManifestWork, string, error) {
+	manifestWork := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: decision.Cluster,
+			Name:      manifestWorkName(deployable),
+			Labels: map[string]string{
+				constants.DeployableLabelNamespace: deployable.Namespace,
+				constants.DeployableLabelName:      deployable.Name,
+			},
+		},
+	}
+
+	type renderedManifest struct {
+		resource corev1.ObjectReference
+		template runtime.RawExtension
+		wave     int32
+	}
+	rendered := make([]renderedManifest, 0, len(decision.Resources))
+	for _, resource := range decision.Resources {
+		manifest := &appsv1alpha1.Manifest{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: appsv1alpha1.ManifestNamespace(resource),
+				Name:      appsv1alpha1.ManifestName(resource),
+			},
+		}
+		if err := c.Client.Get(ctx, client.ObjectKeyFromObject(manifest), manifest); err != nil {
+			klog.ErrorS(err, "unable to get Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+			return nil, "", err
+		}
+
+		wave, err := appsv1alpha1.ManifestApplyWave(manifest, resource)
+		if err != nil {
+			klog.ErrorS(err, "unable to get apply wave of Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+			return nil, "", err
+		}
+
+		template := manifest.Template
+		if replicas, ok := findReplicas(decision.Replicas, resource); ok {
+			rendered, err := setReplicas(template, replicas)
+			if err != nil {
+				klog.ErrorS(err, "unable to set replicas for Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+				return nil, "", err
+			}
+			template = rendered
+		}
+
+		if len(deployable.Spec.Overrides) > 0 {
+			rendered, err := overrides.Apply(c.Client.Scheme(), template, cluster, resource, deployable.Spec.Overrides)
+			if err != nil {
+				klog.ErrorS(err, "unable to apply overrides for Manifest", "namespace", manifest.Namespace, "name", manifest.Name, "cluster", cluster.Name)
+				return nil, "", err
+			}
+			template = rendered
+		}
+
+		rendered = append(rendered, renderedManifest{resource: resource, template: template, wave: wave})
+	}
+	sort.SliceStable(rendered, func(i, j int) bool {
+		return rendered[i].wave < rendered[j].wave
+	})
+
+	// the waves after the first one not available are held back, except the resources applied before
+	var waitingFor string
+	if len(rendered) > 0 {
+		appliedWave := rendered[len(rendered)-1].wave
+		for _, r := range rendered {
+			if r.wave >= appliedWave {
+				break
+			}
+			if !resourceAvailable(existing, r.template) {
+				appliedWave = r.wave
+				waitingFor = fmt.Sprintf("apply wave %d", r.wave)
+				break
+			}
+		}
+
+		applied := appliedResources(existing)
+		kept := rendered[:0]
+		for _, r := range rendered {
+			if r.wave <= appliedWave || applied.Has(groupKindKey(r.template)) {
+				kept = append(kept, r)
+			}
+		}
+		rendered = kept
+	}
+
+	manifestWork.Spec.Workload.Manifests = make([]workv1.Manifest, len(rendered))
+	for idx, r := range rendered {
+		manifestWork.Spec.Workload.Manifests[idx] = workv1.Manifest{
+			RawExtension: r.template,
+		}
+
+		if config, ok := c.manifestConfig(r.resource); ok {
+			manifestWork.Spec.ManifestConfigs = append(manifestWork.Spec.ManifestConfigs, config)
+		}
+	}
+
+	return manifestWork, waitingFor, nil
+}
+
+// resourceAvailable returns true if the resource is available in the existing ManifestWork
+func resourceAvailable(existing *workv1.ManifestWork, template runtime.RawExtension) bool {
+	if existing == nil {
+		return false
+	}
+	// the resource is matched by the template, the namespace of cluster-scoped resources is left out in the ManifestWork
+	resource, err := templateResource(template)
+	if err != nil {
+		return false
+	}
+	gvk := schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind)
+	condition, ok := findManifestCondition(existing.Status.ResourceStatus.Manifests, gvk, resource)
+	return ok && meta.IsStatusConditionTrue(condition.Conditions, workv1.WorkAvailable)
+}
+
+// appliedResources returns the keys of resources in the existing ManifestWork, see groupKindKey
+func appliedResources(existing *workv1.ManifestWork) sets.String {
+	applied := sets.NewString()
+	if existing == nil {
+		return applied
+	}
+	for _, manifest := range existing.Spec.Workload.Manifests {
+		if key := groupKindKey(manifest.RawExtension); key != "" {
+			applied.Insert(key)
+		}
+	}
+	return applied
+}
+
+// groupKindKey returns group/kind/namespace/name of the template, the version is left out
+// as it may change between the updates
+func groupKindKey(template runtime.RawExtension) string {
+	resource, err := templateResource(template)
+	if err != nil {
+		return ""
+	}
+	gvk := schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind)
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, resource.Namespace, resource.Name)
+}
+
+// manifestConfig returns the status feedback rules of the resource, which are written back by ManifestStatusController
+func (c *ManifestWorkController) manifestConfig(resource corev1.ObjectReference) (workv1.ManifestConfigOption, bool) {
+	gvk := schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind)
+	mapping, err := c.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		klog.V(1).InfoS("unable to map resource, skip status feedback", "apiVersion", resource.APIVersion, "kind", resource.Kind, "err", err)
+		return workv1.ManifestConfigOption{}, false
+	}
+
+	rule := workv1.FeedbackRule{Type: workv1.WellKnownStatusType}
+	if isWorkload(gvk) {
+		rule = workv1.FeedbackRule{
+			Type: workv1.JSONPathsType,
+			JsonPaths: []workv1.JsonPath{
+				{Name: feedbackReplicas, Path: ".replicas"},
+				{Name: feedbackReadyReplicas, Path: ".readyReplicas"},
+				{Name: feedbackAvailableReplicas, Path: ".availableReplicas"},
+				{Name: feedbackUpdatedReplicas, Path: ".updatedReplicas"},
+				{Name: feedbackObservedGeneration, Path: ".observedGeneration"},
+			},
+		}
+	}
+
+	return workv1.ManifestConfigOption{
+		ResourceIdentifier: workv1.ResourceIdentifier{
+			Group:     gvk.Group,
+			Resource:  mapping.Resource.Resource,
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+		},
+		FeedbackRules: []workv1.FeedbackRule{rule},
+	}, true
+}
+
+// computeHash returns the hash of the ManifestWorks, including the clusters, the labels and the rendered specs
+func computeHash(manifestWorks []*workv1.ManifestWork) (string, error) {
+	hasher := sha256.New()
+	for _, manifestWork := range manifestWorks {
+		data, err := json.Marshal(manifestWork.Spec)
+		if err != nil {
+			return "", err
+		}
+		// the keys of map are sorted when printing
+		fmt.Fprintf(hasher, "%s/%s:%v:", manifestWork.Namespace, manifestWork.Name, manifestWork.Labels)
+		hasher.Write(data)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// findReplicas returns the replicas divided to the cluster for the resource
+func findReplicas(replicas []appsv1alpha1.ResourceReplicas, resource corev1.ObjectReference) (int32, bool) {
+	for _, r := range replicas {
+		if r.Resource.APIVersion == resource.APIVersion && r.Resource.Kind == resource.Kind &&
+			r.Resource.Namespace == resource.Namespace && r.Resource.Name == resource.Name {
+			return r.Replicas, true
+		}
+	}
+	return 0, false
+}
+
+// setReplicas renders spec.replicas into the template
+func setReplicas(template runtime.RawExtension, replicas int32) (runtime.RawExtension, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(template.Raw); err != nil {
+		return runtime.RawExtension{}, err
+	}
+	if err := unstructured.SetNestedField(obj.Object, int64(replicas), "spec", "replicas"); err != nil {
+		return runtime.RawExtension{}, err
+	}
+	raw, err := obj.MarshalJSON()
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
 }
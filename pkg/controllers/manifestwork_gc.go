@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	workv1 "open-cluster-management.io/api/work/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+// ManifestWorkGarbageCollector deletes the ManifestWorks whose Deployable is gone, or which are not
+// in the decisions of Deployable any more, e.g. left by the races of finalizer removal
+type ManifestWorkGarbageCollector struct {
+	client.Client
+	// Reader reads the Deployables from apiserver, the decisions in cache may be older than the ManifestWorks
+	client.Reader
+
+	Period time.Duration
+	// DryRun only reports the orphaned ManifestWorks without deleting them
+	DryRun bool
+}
+
+var _ manager.Runnable = &ManifestWorkGarbageCollector{}
+var _ manager.LeaderElectionRunnable = &ManifestWorkGarbageCollector{}
+
+// SetupWithManager sets up the garbage collector with the Manager.
+func (c *ManifestWorkGarbageCollector) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(c)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (c *ManifestWorkGarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the garbage collection periodically until the context is done
+func (c *ManifestWorkGarbageCollector) Start(ctx context.Context) error {
+	klog.InfoS("starting manifestwork garbage collector", "period", c.Period, "dryRun", c.DryRun)
+	wait.UntilWithContext(ctx, c.collect, c.Period)
+	return nil
+}
+
+func (c *ManifestWorkGarbageCollector) collect(ctx context.Context) {
+	manifestWorks := &workv1.ManifestWorkList{}
+	if err := c.Client.List(ctx, manifestWorks, client.HasLabels{constants.DeployableLabelNamespace, constants.DeployableLabelName}); err != nil {
+		klog.ErrorS(err, "unable to list ManifestWorks")
+		return
+	}
+
+	var orphaned, deleted int
+	for i := range manifestWorks.Items {
+		manifestWork := &manifestWorks.Items[i]
+		orphan, reason, err := c.isOrphan(ctx, manifestWork)
+		if err != nil {
+			klog.ErrorS(err, "unable to check ManifestWork", "namespace", manifestWork.Namespace, "name", manifestWork.Name)
+			continue
+		}
+		if !orphan {
+			continue
+		}
+
+		orphaned++
+		if c.DryRun {
+			klog.InfoS("found orphaned ManifestWork", "namespace", manifestWork.Namespace, "name", manifestWork.Name, "reason", reason)
+			continue
+		}
+
+		if err := c.Client.Delete(ctx, manifestWork, client.Preconditions{UID: &manifestWork.UID}); err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "unable to delete orphaned ManifestWork", "namespace", manifestWork.Namespace, "name", manifestWork.Name)
+			continue
+		}
+		deleted++
+		klog.InfoS("success to delete orphaned ManifestWork", "namespace", manifestWork.Namespace, "name", manifestWork.Name, "reason", reason)
+	}
+
+	klog.InfoS("manifestwork garbage collection finished", "total", len(manifestWorks.Items), "orphaned", orphaned, "deleted", deleted, "dryRun", c.DryRun)
+}
+
+// isOrphan returns true with the reason if the ManifestWork is not expected by its Deployable
+func (c *ManifestWorkGarbageCollector) isOrphan(ctx context.Context, manifestWork *workv1.ManifestWork) (bool, string, error) {
+	key := client.ObjectKey{
+		Namespace: manifestWork.Labels[constants.DeployableLabelNamespace],
+		Name:      manifestWork.Labels[constants.DeployableLabelName],
+	}
+
+	deployable := &appsv1alpha1.Deployable{}
+	if err := c.Reader.Get(ctx, key, deployable); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, "DeployableNotFound", nil
+		}
+		return false, "", err
+	}
+
+	// the deleting Deployable cleans up its ManifestWorks by finalizer
+	if !deployable.DeletionTimestamp.IsZero() {
+		return false, "", nil
+	}
+
+	for _, decision := range deployable.Status.PlacementDecisions {
+		if decision.Cluster == manifestWork.Namespace && manifestWorkName(deployable) == manifestWork.Name {
+			return false, "", nil
+		}
+	}
+	return true, "ClusterNotDecided", nil
+}
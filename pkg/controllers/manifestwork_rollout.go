@@ -0,0 +1,314 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+const (
+	// defaultProgressDeadline is the time for the workloads of an updated cluster to be ready before the rollout halts
+	defaultProgressDeadline = 10 * time.Minute
+	// rolloutCheckPeriod is the period to check the progress of rollout
+	rolloutCheckPeriod = 30 * time.Second
+)
+
+// rolloutRecord is recorded on the ManifestWork updated by the rollout strategy, the workloads are ready only
+// after their controllers observe the generations newer than the recorded ones, so the status reported before
+// the update is not taken as the one of the updated workloads
+type rolloutRecord struct {
+	UpdateTime          metav1.Time      `json:"updateTime"`
+	ObservedGenerations map[string]int64 `json:"observedGenerations,omitempty"`
+}
+
+// planRollout selects the ManifestWorks to apply by the rollout strategy of Deployable, the existing ones are keyed
+// by cluster. The clusters of outdated ManifestWorks held back are recorded in held, and the progress is returned
+func planRollout(deployable *appsv1alpha1.Deployable, manifestWorks []*workv1.ManifestWork, existing map[string]*workv1.ManifestWork,
+	held sets.String, now time.Time) ([]*workv1.ManifestWork, *appsv1alpha1.RolloutStatus) {
+	strategy := deployable.Spec.RolloutStrategy
+	if strategy == nil {
+		return manifestWorks, nil
+	}
+
+	total := len(deployable.Status.PlacementDecisions)
+	batchSize := scaledValue(strategy.BatchSize, intstr.FromInt(1), total, true)
+	maxUnavailable := scaledValue(strategy.MaxUnavailable, intstr.FromInt(batchSize), total, false)
+	progressDeadline := defaultProgressDeadline
+	if strategy.ProgressDeadlineSeconds != nil {
+		progressDeadline = time.Duration(*strategy.ProgressDeadlineSeconds) * time.Second
+	}
+
+	var unavailable, inFlight int
+	for _, manifestWork := range existing {
+		if !rolloutReady(manifestWork) {
+			unavailable++
+		}
+	}
+
+	applying := make([]*workv1.ManifestWork, 0, len(manifestWorks))
+	var outdated []*workv1.ManifestWork
+	var degraded []string
+	for _, manifestWork := range manifestWorks {
+		current, ok := existing[manifestWork.Namespace]
+		// nothing runs in the clusters placed newly, so they are not held back
+		if !ok {
+			setRolloutRecord(manifestWork, nil, now)
+			applying = append(applying, manifestWork)
+			continue
+		}
+		if !manifestWorkUpdated(current, manifestWork) {
+			outdated = append(outdated, manifestWork)
+			continue
+		}
+
+		applying = append(applying, manifestWork)
+		if rolloutReady(current) {
+			continue
+		}
+		inFlight++
+		if meta.IsStatusConditionTrue(current.Status.Conditions, workv1.WorkDegraded) || rolloutExpired(current, progressDeadline, now) {
+			degraded = append(degraded, manifestWork.Namespace)
+		}
+	}
+	// the unavailable clusters go first, updating them makes nothing worse
+	sort.SliceStable(outdated, func(i, j int) bool {
+		return !rolloutReady(existing[outdated[i].Namespace]) && rolloutReady(existing[outdated[j].Namespace])
+	})
+
+	status := &appsv1alpha1.RolloutStatus{
+		Phase:         appsv1alpha1.RolloutPhaseProgressing,
+		TotalClusters: int32(total),
+	}
+	switch {
+	case len(outdated) == 0:
+		status.Phase = appsv1alpha1.RolloutPhaseCompleted
+	case strategy.Paused:
+		status.Phase = appsv1alpha1.RolloutPhasePaused
+		status.Message = "rollout is paused"
+	case len(degraded) > 0:
+		status.Phase = appsv1alpha1.RolloutPhaseHalted
+		status.Message = fmt.Sprintf("updated clusters are degraded or not ready in %s: %s", progressDeadline, strings.Join(degraded, ", "))
+	default:
+		waiting := outdated[:0]
+		for _, manifestWork := range outdated {
+			if inFlight >= batchSize {
+				waiting = append(waiting, manifestWork)
+				continue
+			}
+			current := existing[manifestWork.Namespace]
+			// updating an available cluster may make it unavailable
+			if rolloutReady(current) {
+				if unavailable >= maxUnavailable {
+					waiting = append(waiting, manifestWork)
+					continue
+				}
+				unavailable++
+			}
+			inFlight++
+			setRolloutRecord(manifestWork, current, now)
+			applying = append(applying, manifestWork)
+		}
+		outdated = waiting
+		status.Message = fmt.Sprintf("updating %d clusters in batches of %d, max unavailable %d", inFlight, batchSize, maxUnavailable)
+	}
+
+	for _, manifestWork := range outdated {
+		held.Insert(manifestWork.Namespace)
+	}
+	status.UpdatedClusters = int32(len(applying))
+	return applying, status
+}
+
+// manifestWorkUpdated returns true if the existing ManifestWork is updated to the generated one
+func manifestWorkUpdated(existing, generated *workv1.ManifestWork) bool {
+	for k, v := range generated.Labels {
+		if existing.Labels[k] != v {
+			return false
+		}
+	}
+	return apiequality.Semantic.DeepEqual(existing.Spec, generated.Spec)
+}
+
+// rolloutReady returns true if the ManifestWork is applied and available in its current generation and not degraded,
+// and the workloads in it have all the desired replicas updated, ready and available
+func rolloutReady(manifestWork *workv1.ManifestWork) bool {
+	if !conditionCurrent(manifestWork, workv1.WorkApplied) || !conditionCurrent(manifestWork, workv1.WorkAvailable) ||
+		meta.IsStatusConditionTrue(manifestWork.Status.Conditions, workv1.WorkDegraded) {
+		return false
+	}
+
+	record := rolloutRecordOf(manifestWork)
+	for _, manifest := range manifestWork.Spec.Workload.Manifests {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(manifest.Raw); err != nil {
+			return false
+		}
+		gvk := obj.GroupVersionKind()
+		if !isWorkload(gvk) {
+			continue
+		}
+
+		desired, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if err != nil {
+			return false
+		}
+		if !found {
+			desired = 1
+		}
+
+		condition, ok := findManifestCondition(manifestWork.Status.ResourceStatus.Manifests, gvk,
+			corev1.ObjectReference{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+		if !ok {
+			return false
+		}
+		values := condition.StatusFeedbacks.Values
+		observedGeneration, ok := feedbackValue(values, feedbackObservedGeneration)
+		if !ok || observedGeneration <= record.ObservedGenerations[workloadKey(gvk, obj.GetNamespace(), obj.GetName())] {
+			return false
+		}
+		// the counters of zero are omitted from the status of workloads
+		replicas, _ := feedbackValue(values, feedbackReplicas)
+		readyReplicas, _ := feedbackValue(values, feedbackReadyReplicas)
+		availableReplicas, _ := feedbackValue(values, feedbackAvailableReplicas)
+		if replicas != desired || readyReplicas != desired || availableReplicas != desired {
+			return false
+		}
+		// ReplicaSets do not report updatedReplicas, all their replicas are of the current template
+		if updatedReplicas, _ := feedbackValue(values, feedbackUpdatedReplicas); gvk.Kind != "ReplicaSet" && updatedReplicas != desired {
+			return false
+		}
+	}
+	return true
+}
+
+// rolloutExpired returns true if the ManifestWork is not ready within the progress deadline since it is updated
+func rolloutExpired(manifestWork *workv1.ManifestWork, progressDeadline time.Duration, now time.Time) bool {
+	record := rolloutRecordOf(manifestWork)
+	return !record.UpdateTime.IsZero() && now.Sub(record.UpdateTime.Time) > progressDeadline
+}
+
+// setRolloutRecord records the update time and the generations observed in the existing ManifestWork of the workloads
+// whose spec is changed, the generations of others are not bumped by the update
+func setRolloutRecord(manifestWork, existing *workv1.ManifestWork, now time.Time) {
+	record := rolloutRecord{UpdateTime: metav1.NewTime(now)}
+	if existing != nil {
+		previous, updated := workloadSpecs(existing), workloadSpecs(manifestWork)
+		for _, condition := range existing.Status.ResourceStatus.Manifests {
+			resourceMeta := condition.ResourceMeta
+			gvk := schema.GroupVersionKind{Group: resourceMeta.Group, Version: resourceMeta.Version, Kind: resourceMeta.Kind}
+			key := workloadKey(gvk, resourceMeta.Namespace, resourceMeta.Name)
+			if !isWorkload(gvk) || apiequality.Semantic.DeepEqual(previous[key], updated[key]) {
+				continue
+			}
+			if observedGeneration, ok := feedbackValue(condition.StatusFeedbacks.Values, feedbackObservedGeneration); ok {
+				if record.ObservedGenerations == nil {
+					record.ObservedGenerations = make(map[string]int64)
+				}
+				record.ObservedGenerations[key] = observedGeneration
+			}
+		}
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		klog.ErrorS(err, "unable to marshal rollout record", "namespace", manifestWork.Namespace, "name", manifestWork.Name)
+		return
+	}
+	if manifestWork.Annotations == nil {
+		manifestWork.Annotations = make(map[string]string, 1)
+	}
+	manifestWork.Annotations[constants.ManifestWorkRolloutAnnotation] = string(data)
+}
+
+// rolloutRecordOf returns the rollout record of ManifestWork, an empty one if not updated by the rollout strategy
+func rolloutRecordOf(manifestWork *workv1.ManifestWork) rolloutRecord {
+	record := rolloutRecord{}
+	if data, ok := manifestWork.Annotations[constants.ManifestWorkRolloutAnnotation]; ok {
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			klog.V(1).InfoS("unable to unmarshal rollout record", "namespace", manifestWork.Namespace, "name", manifestWork.Name, "err", err)
+		}
+	}
+	return record
+}
+
+// workloadSpecs returns the spec of workloads in ManifestWork keyed by workloadKey
+func workloadSpecs(manifestWork *workv1.ManifestWork) map[string]interface{} {
+	specs := make(map[string]interface{})
+	for _, manifest := range manifestWork.Spec.Workload.Manifests {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(manifest.Raw); err != nil {
+			continue
+		}
+		if gvk := obj.GroupVersionKind(); isWorkload(gvk) {
+			specs[workloadKey(gvk, obj.GetNamespace(), obj.GetName())] = obj.Object["spec"]
+		}
+	}
+	return specs
+}
+
+// conditionCurrent returns true if the condition of ManifestWork is true, and observes its current generation
+// if the observedGeneration is reported
+func conditionCurrent(manifestWork *workv1.ManifestWork, conditionType string) bool {
+	condition := meta.FindStatusCondition(manifestWork.Status.Conditions, conditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		return false
+	}
+	return condition.ObservedGeneration == 0 || condition.ObservedGeneration == manifestWork.Generation
+}
+
+func feedbackValue(values []workv1.FeedbackValue, name string) (int64, bool) {
+	for _, value := range values {
+		if value.Name == name && value.Value.Type == workv1.Integer && value.Value.Integer != nil {
+			return *value.Value.Integer, true
+		}
+	}
+	return 0, false
+}
+
+func workloadKey(gvk schema.GroupVersionKind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, namespace, name)
+}
+
+// scaledValue resolves the number or percentage of the total clusters, at least 1
+func scaledValue(value *intstr.IntOrString, defaultValue intstr.IntOrString, total int, roundUp bool) int {
+	if value == nil {
+		value = &defaultValue
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(value, total, roundUp)
+	if err != nil || scaled < 1 {
+		return 1
+	}
+	return scaled
+}
@@ -23,6 +23,7 @@ import (
 	"open-cluster-management.io/api/work/v1"
 
 	"github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
 )
 
 // CRDsInstalled checks if the CRDs are installed or not
@@ -30,6 +31,7 @@ func CRDsInstalled(discovery *discovery.DiscoveryClient) bool {
 	gvs := []schema.GroupVersionKind{
 		v1alpha1.SchemeGroupVersion.WithKind("Manifest"),
 		v1alpha1.SchemeGroupVersion.WithKind("Deployable"),
+		clusterv1alpha1.SchemeGroupVersion.WithKind("Cluster"),
 		v1.GroupVersion.WithKind("ManifestWork"),
 	}
 
@@ -25,6 +25,9 @@ import (
 const (
 	// Shadow all the Kubernetes objects, including CRDs.
 	ShadowAPI featuregate.Feature = "ShadowAPI"
+
+	// Cache the resources of member clusters in the gateway, see --cached-resources of apiserver.
+	ClusterCache featuregate.Feature = "ClusterCache"
 )
 
 func init() {
@@ -34,5 +37,6 @@ func init() {
 // defaultFeatureGates consists of all known Kubernetes-specific and feature keys.
 // To add a new feature, define a key for it above and add it here.
 var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-	ShadowAPI: {Default: false, PreRelease: featuregate.Alpha, LockToDefault: false},
+	ShadowAPI:    {Default: false, PreRelease: featuregate.Alpha, LockToDefault: false},
+	ClusterCache: {Default: false, PreRelease: featuregate.Alpha, LockToDefault: false},
 }
@@ -19,8 +19,10 @@ package apiserver
 import (
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/admission"
 	genericapiserver "k8s.io/apiserver/pkg/server"
@@ -28,6 +30,7 @@ import (
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/component-base/logs"
 	netutils "k8s.io/utils/net"
+	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/multi-cluster-platform/mcp/pkg/apiserver"
 	"github.com/multi-cluster-platform/mcp/pkg/options/common"
@@ -36,6 +39,9 @@ import (
 type Options struct {
 	EnablesLocalDebug bool
 
+	// CachedResources is the resources of member clusters cached by the gateway, in the format of apiVersion/resource
+	CachedResources []string
+
 	CommonOptions *common.Options
 	Log           *logs.Options
 
@@ -60,11 +66,34 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 
 	flags.BoolVar(&o.EnablesLocalDebug, "enable-local-debug", false,
 		"Under the local-debug mode the apiserver will allow all access to its resources without authorizing the requests, this flag is only intended for debugging in your workstation.")
+
+	flags.StringSliceVar(&o.CachedResources, "cached-resources", []string{"v1/pods", "v1/services", "v1/configmaps", "apps/v1/deployments"},
+		"Resources in the format of apiVersion/resource, which are cached from member clusters when the ClusterCache feature is enabled.")
 }
 
 // Validate checks Options and return a slice of found errs.
 func (o *Options) Validate() field.ErrorList {
-	return nil
+	var errs field.ErrorList
+	for i, resource := range o.CachedResources {
+		if _, err := ParseCachedResource(resource); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("cached-resources").Index(i), resource, err.Error()))
+		}
+	}
+	return errs
+}
+
+// ParseCachedResource parses the resource in the format of apiVersion/resource, e.g. apps/v1/deployments, v1/pods
+func ParseCachedResource(resource string) (schema.GroupVersionResource, error) {
+	i := strings.LastIndex(resource, "/")
+	if i <= 0 || i == len(resource)-1 {
+		return schema.GroupVersionResource{}, fmt.Errorf("expect apiVersion/resource")
+	}
+
+	gv, err := schema.ParseGroupVersion(resource[:i])
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return gv.WithResource(resource[i+1:]), nil
 }
 
 // Config fills in fields required to have valid data
@@ -92,9 +121,26 @@ func (o *Options) Config() (*apiserver.Config, error) {
 		return nil, err
 	}
 
+	kubeConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var cachedResources []schema.GroupVersionResource
+	for _, resource := range o.CachedResources {
+		gvr, err := ParseCachedResource(resource)
+		if err != nil {
+			return nil, err
+		}
+		cachedResources = append(cachedResources, gvr)
+	}
+
 	config := &apiserver.Config{
 		GenericConfig: serverConfig,
-		ExtraConfig:   &apiserver.ExtraConfig{},
+		ExtraConfig: &apiserver.ExtraConfig{
+			KubeConfig:      kubeConfig,
+			CachedResources: cachedResources,
+		},
 	}
 	return config, nil
 }
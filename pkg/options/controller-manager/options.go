@@ -17,7 +17,12 @@ limitations under the License.
 package controllermanager
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
@@ -36,7 +41,28 @@ type Options struct {
 	ProbeAddr   string
 	MetricsAddr string
 
-	ConcurrencyManifestWork int
+	EnableWebhook  bool
+	WebhookPort    int
+	WebhookCertDir string
+
+	ConcurrencyManifestWork    int
+	ConcurrencyManifestStatus  int
+	ConcurrencyManifestCapture int
+	ConcurrencyCluster         int
+	ConcurrencyClusterResource int
+
+	ClusterHealthCheckPeriod time.Duration
+
+	// ClusterResourceSyncPeriod is the period to collect the resource summary of member clusters
+	ClusterResourceSyncPeriod time.Duration
+	// NodePoolLabels are the labels grouping the nodes of member clusters into pools
+	NodePoolLabels []string
+
+	ManifestWorkGCPeriod time.Duration
+	ManifestWorkGCDryRun bool
+
+	// CaptureResources is the resources captured into Manifests, in the format of apiVersion/kind
+	CaptureResources []string
 
 	CommonOptions *common.Options
 	Log           *logs.Options
@@ -69,14 +95,79 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.MetricsAddr, "metrics-bind-address", "0",
 		"The address the metric endpoint binds to.")
 
+	flags.BoolVar(&o.EnableWebhook, "enable-webhook", false,
+		"Enable the admission webhooks of Deployables and Manifests.")
+
+	flags.IntVar(&o.WebhookPort, "webhook-port", 9443,
+		"The port the webhook server serves at.")
+
+	flags.StringVar(&o.WebhookCertDir, "webhook-cert-dir", "",
+		"The directory that contains tls.crt and tls.key of the webhook server, defaults to <temp-dir>/k8s-webhook-server/serving-certs.")
+
 	flags.BoolVar(&o.LeaderElection.LeaderElect, "leader-elect", true,
 		"Enable leader elect.")
 
 	flags.IntVar(&o.ConcurrencyManifestWork, "concurrency-manifestwork", 10,
 		"Concurrency of ManifestWork controller.")
+
+	flags.IntVar(&o.ConcurrencyManifestStatus, "concurrency-manifeststatus", 10,
+		"Concurrency of Manifest status controller.")
+
+	flags.IntVar(&o.ConcurrencyManifestCapture, "concurrency-manifestcapture", 5,
+		"Concurrency of Manifest capture controller for each resource.")
+
+	flags.IntVar(&o.ConcurrencyCluster, "concurrency-cluster", 5,
+		"Concurrency of Cluster controller.")
+
+	flags.IntVar(&o.ConcurrencyClusterResource, "concurrency-clusterresource", 5,
+		"Concurrency of Cluster resource controller.")
+
+	flags.DurationVar(&o.ClusterHealthCheckPeriod, "cluster-health-check-period", time.Minute,
+		"Period to check the health of member clusters.")
+
+	flags.DurationVar(&o.ClusterResourceSyncPeriod, "cluster-resource-sync-period", time.Minute,
+		"Period to collect the resource summary of member clusters through the gateway.")
+
+	flags.StringSliceVar(&o.NodePoolLabels, "node-pool-labels", []string{"cloud.google.com/gke-nodepool", "eks.amazonaws.com/nodegroup", "kubernetes.azure.com/agentpool"},
+		"Labels grouping the nodes of member clusters into pools, the first one present on the node is used, the nodes without them are in the default pool.")
+
+	flags.DurationVar(&o.ManifestWorkGCPeriod, "manifestwork-gc-period", 10*time.Minute,
+		"Period to collect the orphaned ManifestWorks, 0 disables the garbage collector.")
+
+	flags.BoolVar(&o.ManifestWorkGCDryRun, "manifestwork-gc-dry-run", false,
+		"Only report the orphaned ManifestWorks without deleting them.")
+
+	flags.StringSliceVar(&o.CaptureResources, "capture-resources", []string{"apps/v1/Deployment", "apps/v1/StatefulSet", "apps/v1/DaemonSet"},
+		"Resources in the format of apiVersion/kind, which are captured into Manifests when annotated by manifest.apps.mcp.io/capture=true.")
 }
 
 // Validate checks Options and return a slice of found errs.
 func (o *Options) Validate() field.ErrorList {
-	return nil
+	var errs field.ErrorList
+	if o.ClusterHealthCheckPeriod <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("cluster-health-check-period"), o.ClusterHealthCheckPeriod, "must be greater than 0"))
+	}
+	if o.ClusterResourceSyncPeriod <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("cluster-resource-sync-period"), o.ClusterResourceSyncPeriod, "must be greater than 0"))
+	}
+	for i, resource := range o.CaptureResources {
+		if _, err := ParseCaptureResource(resource); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("capture-resources").Index(i), resource, err.Error()))
+		}
+	}
+	return errs
+}
+
+// ParseCaptureResource parses the resource in the format of apiVersion/kind, e.g. apps/v1/Deployment, v1/Service
+func ParseCaptureResource(resource string) (schema.GroupVersionKind, error) {
+	i := strings.LastIndex(resource, "/")
+	if i <= 0 || i == len(resource)-1 {
+		return schema.GroupVersionKind{}, fmt.Errorf("expect apiVersion/kind")
+	}
+
+	gv, err := schema.ParseGroupVersion(resource[:i])
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+	return gv.WithKind(resource[i+1:]), nil
 }
@@ -36,6 +36,9 @@ type Options struct {
 	ProbeAddr   string
 	MetricsAddr string
 
+	// ConfigFile is the path of scheduler profile, the default profile is used if empty
+	ConfigFile string
+
 	CommonOptions *common.Options
 	Log           *logs.Options
 
@@ -69,6 +72,9 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 
 	flags.BoolVar(&o.LeaderElection.LeaderElect, "leader-elect", true,
 		"Enable leader elect.")
+
+	flags.StringVar(&o.ConfigFile, "config", "",
+		"The path to the scheduler profile file, the default plugins are used if not set.")
 }
 
 // Validate checks Options and return a slice of found errs.
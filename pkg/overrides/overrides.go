@@ -0,0 +1,262 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overrides
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+)
+
+// Apply renders the overrides selecting the cluster and the resource into the template,
+// the scheme provides the patch strategies of built-in kinds for strategic merge patch
+func Apply(scheme *runtime.Scheme, template runtime.RawExtension, cluster *clusterv1alpha1.Cluster, resource corev1.ObjectReference,
+	overrides []appsv1alpha1.Override) (runtime.RawExtension, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(template.Raw); err != nil {
+		return runtime.RawExtension{}, err
+	}
+
+	applied := false
+	for i := range overrides {
+		override := &overrides[i]
+		matched, err := matchCluster(override, cluster)
+		if err != nil {
+			return runtime.RawExtension{}, fmt.Errorf("overrides[%d]: %v", i, err)
+		}
+		if !matched || !matchResource(override, resource) {
+			continue
+		}
+
+		if err := applyOverriders(scheme, obj, &override.Overriders); err != nil {
+			return runtime.RawExtension{}, fmt.Errorf("overrides[%d]: %v", i, err)
+		}
+		applied = true
+	}
+	if !applied {
+		return template, nil
+	}
+
+	raw, err := obj.MarshalJSON()
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
+
+func matchCluster(override *appsv1alpha1.Override, cluster *clusterv1alpha1.Cluster) (bool, error) {
+	for _, name := range override.ClusterNames {
+		if name == cluster.Name {
+			return true, nil
+		}
+	}
+	if override.ClusterSelector == nil {
+		// the override without any cluster scope selects all clusters
+		return len(override.ClusterNames) == 0, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(override.ClusterSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(cluster.Labels)), nil
+}
+
+func matchResource(override *appsv1alpha1.Override, resource corev1.ObjectReference) bool {
+	if len(override.Resources) == 0 {
+		return true
+	}
+	for _, r := range override.Resources {
+		if r.APIVersion == resource.APIVersion && r.Kind == resource.Kind && r.Namespace == resource.Namespace && r.Name == resource.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func applyOverriders(scheme *runtime.Scheme, obj *unstructured.Unstructured, overriders *appsv1alpha1.Overriders) error {
+	if len(overriders.Labels) > 0 {
+		obj.SetLabels(mergeStringMap(obj.GetLabels(), overriders.Labels))
+	}
+	if len(overriders.Annotations) > 0 {
+		obj.SetAnnotations(mergeStringMap(obj.GetAnnotations(), overriders.Annotations))
+	}
+
+	if overriders.ImageRegistry != "" || len(overriders.Commands) > 0 || len(overriders.Args) > 0 {
+		if err := overrideContainers(obj, overriders); err != nil {
+			return err
+		}
+	}
+
+	if overriders.StrategicMergePatch != nil && len(overriders.StrategicMergePatch.Raw) > 0 {
+		if err := strategicMergePatch(scheme, obj, overriders.StrategicMergePatch.Raw); err != nil {
+			return fmt.Errorf("strategic merge patch: %v", err)
+		}
+	}
+
+	if len(overriders.JSONPatches) > 0 {
+		if err := applyJSONPatch(obj, overriders.JSONPatches); err != nil {
+			return fmt.Errorf("json patch: %v", err)
+		}
+	}
+	return nil
+}
+
+// podSpecPaths is the path of pod spec in the built-in workload kinds
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// overrideContainers replaces the image registry, commands and args of the containers in pod spec
+func overrideContainers(obj *unstructured.Unstructured, overriders *appsv1alpha1.Overriders) error {
+	path, ok := podSpecPaths[obj.GetKind()]
+	if !ok {
+		return nil
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, found, err := unstructured.NestedSlice(obj.Object, append(path, field)...)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+
+		for i := range containers {
+			container, ok := containers[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+
+			if image, ok := container["image"].(string); ok && overriders.ImageRegistry != "" {
+				container["image"] = replaceRegistry(image, overriders.ImageRegistry)
+			}
+			for _, command := range overriders.Commands {
+				if command.ContainerName == name {
+					container["command"] = toInterfaceSlice(command.Command)
+				}
+			}
+			for _, args := range overriders.Args {
+				if args.ContainerName == name {
+					container["args"] = toInterfaceSlice(args.Args)
+				}
+			}
+		}
+
+		if err := unstructured.SetNestedSlice(obj.Object, containers, append(path, field)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceRegistry replaces the registry of image, e.g. nginx:1.21 => example.com/nginx:1.21,
+// docker.io/library/nginx:1.21 => example.com/library/nginx:1.21
+func replaceRegistry(image, registry string) string {
+	name := image
+	if i := strings.IndexRune(image, '/'); i >= 0 {
+		// the first component is the registry only if it looks like a host
+		host := image[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			name = image[i+1:]
+		}
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + name
+}
+
+func strategicMergePatch(scheme *runtime.Scheme, obj *unstructured.Unstructured, patch []byte) error {
+	original, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	if typed, err := scheme.New(obj.GroupVersionKind()); err == nil {
+		patched, err = strategicpatch.StrategicMergePatch(original, patch, typed)
+		if err != nil {
+			return err
+		}
+	} else {
+		// no patch strategies for custom resources
+		patched, err = jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return err
+		}
+	}
+
+	return obj.UnmarshalJSON(patched)
+}
+
+func applyJSONPatch(obj *unstructured.Unstructured, operations []appsv1alpha1.JSONPatchOperation) error {
+	data, err := json.Marshal(operations)
+	if err != nil {
+		return err
+	}
+	patch, err := jsonpatch.DecodePatch(data)
+	if err != nil {
+		return err
+	}
+
+	original, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	patched, err := patch.Apply(original)
+	if err != nil {
+		return err
+	}
+
+	return obj.UnmarshalJSON(patched)
+}
+
+func mergeStringMap(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func toInterfaceSlice(s []string) []interface{} {
+	result := make([]interface{}, len(s))
+	for i := range s {
+		result[i] = s[i]
+	}
+	return result
+}
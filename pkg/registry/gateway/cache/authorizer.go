@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apiserver/pkg/authentication/user"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	gatewayproxy "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/proxy"
+)
+
+const (
+	// decisionTTL is the period a decision is trusted before asking the member cluster again
+	decisionTTL = time.Minute
+	// staleDecisionTTL is the period a decision is still used when the member cluster is unreachable
+	staleDecisionTTL = 10 * time.Minute
+
+	decisionCacheSize = 4096
+)
+
+// authorizer authorizes the reads from the cache by SubjectAccessReviews against the member cluster,
+// so that the same RBAC is enforced as the requests proxied with impersonation
+type authorizer struct {
+	client    authorizationclient.SubjectAccessReviewInterface
+	decisions *utilcache.LRUExpireCache
+}
+
+type decision struct {
+	allowed bool
+	reason  string
+	time    time.Time
+}
+
+func newAuthorizer(client authorizationclient.SubjectAccessReviewInterface) *authorizer {
+	return &authorizer{
+		client:    client,
+		decisions: utilcache.NewLRUExpireCache(decisionCacheSize),
+	}
+}
+
+// authorize returns whether the requester is allowed, or an error if no decision could be made
+func (a *authorizer) authorize(ctx context.Context, requester user.Info, attributes *authorizationv1.ResourceAttributes) (bool, string, error) {
+	impersonation := gatewayproxy.ImpersonationConfig(requester)
+	spec := authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: attributes,
+		User:               impersonation.UserName,
+		Groups:             impersonation.Groups,
+		UID:                requester.GetUID(),
+	}
+	if len(impersonation.Extra) != 0 {
+		spec.Extra = map[string]authorizationv1.ExtraValue{}
+		for key, values := range impersonation.Extra {
+			spec.Extra[key] = values
+		}
+	}
+
+	// the keys of maps are sorted by json, so the same spec always has the same key
+	data, err := json.Marshal(&spec)
+	if err != nil {
+		return false, "", err
+	}
+	key := string(data)
+
+	cached, exist := a.decisions.Get(key)
+	if exist && time.Since(cached.(*decision).time) < decisionTTL {
+		return cached.(*decision).allowed, cached.(*decision).reason, nil
+	}
+
+	review, err := a.client.Create(ctx, &authorizationv1.SubjectAccessReview{Spec: spec}, metav1.CreateOptions{})
+	if err != nil {
+		if exist {
+			return cached.(*decision).allowed, cached.(*decision).reason, nil
+		}
+		return false, "", err
+	}
+
+	a.decisions.Add(key, &decision{
+		allowed: review.Status.Allowed,
+		reason:  review.Status.Reason,
+		time:    time.Now(),
+	}, staleDecisionTTL)
+	return review.Status.Allowed, review.Status.Reason, nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+const (
+	// syncPeriod is the period to sync the informers with the registered Clusters
+	syncPeriod = 30 * time.Second
+	// buildTimeout bounds the discovery of a member cluster, so an unreachable one does not hold back the others
+	buildTimeout = 10 * time.Second
+)
+
+// Cache keeps the resources of member clusters in memory with informers, so that the gateway
+// serves the reads without reaching the member clusters
+type Cache struct {
+	reader    client.Reader
+	resources []schema.GroupVersionResource
+
+	lock     sync.RWMutex
+	clusters map[string]*clusterCache
+}
+
+// clusterCache holds the informers of a member cluster
+type clusterCache struct {
+	// generation of the Cluster the informers are built with
+	generation int64
+	// secretVersion is the resourceVersion of the Secret holding the credential the informers are built with
+	secretVersion string
+	// ready is false when the member cluster is not healthy, the cached resources are served to
+	// the reads requiring the latest resourceVersion then
+	ready bool
+
+	stopCh     chan struct{}
+	resources  map[schema.GroupVersionResource]*resourceCache
+	authorizer *authorizer
+}
+
+// resourceCache holds the informer of a resource
+type resourceCache struct {
+	kind       string
+	namespaced bool
+	informer   toolscache.SharedIndexInformer
+}
+
+// New returns a Cache of the resources, the member clusters are read from the registered Clusters
+func New(reader client.Reader, resources []schema.GroupVersionResource) *Cache {
+	return &Cache{
+		reader:    reader,
+		resources: resources,
+		clusters:  map[string]*clusterCache{},
+	}
+}
+
+// Start syncs the informers with the registered Clusters periodically until the context is done
+func (c *Cache) Start(ctx context.Context) {
+	klog.InfoS("starting cluster cache", "resources", c.resources)
+	wait.UntilWithContext(ctx, c.sync, syncPeriod)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for name, cc := range c.clusters {
+		close(cc.stopCh)
+		delete(c.clusters, name)
+	}
+}
+
+func (c *Cache) sync(ctx context.Context) {
+	clusters := &clusterv1alpha1.ClusterList{}
+	if err := c.reader.List(ctx, clusters); err != nil {
+		klog.ErrorS(err, "unable to list Clusters")
+		return
+	}
+
+	// the caches are built concurrently, each within buildTimeout
+	var wg sync.WaitGroup
+	registered := map[string]bool{}
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		registered[cluster.Name] = true
+		ready := meta.IsStatusConditionTrue(cluster.Status.Conditions, clusterv1alpha1.ClusterConditionReady)
+
+		secretVersion, err := c.secretVersion(ctx, cluster)
+		if err != nil {
+			klog.ErrorS(err, "unable to get credential of cluster", "cluster", cluster.Name)
+			continue
+		}
+
+		c.lock.RLock()
+		cc, exist := c.clusters[cluster.Name]
+		c.lock.RUnlock()
+		if exist && cc.generation == cluster.Generation && cc.secretVersion == secretVersion {
+			c.lock.Lock()
+			cc.ready = ready
+			c.lock.Unlock()
+			continue
+		}
+
+		// the endpoint or credential of the cluster may be changed, rebuild the informers
+		wg.Add(1)
+		go func(cluster *clusterv1alpha1.Cluster) {
+			defer wg.Done()
+
+			newCC, err := c.newClusterCache(ctx, cluster)
+			if err != nil {
+				klog.ErrorS(err, "unable to build cache", "cluster", cluster.Name)
+				return
+			}
+			newCC.ready = ready
+			newCC.secretVersion = secretVersion
+
+			c.lock.Lock()
+			if old, exist := c.clusters[cluster.Name]; exist {
+				close(old.stopCh)
+			}
+			c.clusters[cluster.Name] = newCC
+			c.lock.Unlock()
+			klog.InfoS("success to build cache", "cluster", cluster.Name, "generation", cluster.Generation, "secretVersion", secretVersion)
+		}(cluster)
+	}
+	wg.Wait()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for name, cc := range c.clusters {
+		if !registered[name] {
+			close(cc.stopCh)
+			delete(c.clusters, name)
+			klog.InfoS("success to remove cache", "cluster", name)
+		}
+	}
+}
+
+// secretVersion returns the resourceVersion of the Secret holding the credential of cluster
+func (c *Cache) secretVersion(ctx context.Context, cluster *clusterv1alpha1.Cluster) (string, error) {
+	secretRef := cluster.Spec.SecretRef
+	if secretRef.Namespace == "" {
+		secretRef.Namespace = constants.SystemNamespace
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.reader.Get(ctx, client.ObjectKey{Namespace: secretRef.Namespace, Name: secretRef.Name}, secret); err != nil {
+		return "", err
+	}
+	return secret.ResourceVersion, nil
+}
+
+func (c *Cache) newClusterCache(ctx context.Context, cluster *clusterv1alpha1.Cluster) (*clusterCache, error) {
+	ctx, cancel := context.WithTimeout(ctx, buildTimeout)
+	defer cancel()
+
+	config, err := clusterutil.RESTConfig(ctx, c.reader, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// the timeout is not set on the config of informers, which would close the watches
+	discoveryConfig := restclient.CopyConfig(config)
+	discoveryConfig.Timeout = buildTimeout
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(discoveryConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create discovery client: %v", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create dynamic client: %v", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create kubernetes client: %v", err)
+	}
+
+	cc := &clusterCache{
+		generation: cluster.Generation,
+		stopCh:     make(chan struct{}),
+		resources:  map[schema.GroupVersionResource]*resourceCache{},
+		authorizer: newAuthorizer(kubeClient.AuthorizationV1().SubjectAccessReviews()),
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	for _, gvr := range c.resources {
+		// the discovery failing as the member cluster is unreachable fails the whole cache, which is built again
+		// in the next sync, otherwise the resources skipped would never be cached
+		resource, err := serverResource(discoveryClient, gvr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to discover resource %s: %v", gvr, err)
+		}
+		if resource == nil {
+			// the resource may be not served by this cluster, e.g. a CRD installed in part of the clusters
+			klog.V(4).InfoS("skip cache of resource not served", "cluster", cluster.Name, "resource", gvr)
+			continue
+		}
+
+		cc.resources[gvr] = &resourceCache{
+			kind:       resource.Kind,
+			namespaced: resource.Namespaced,
+			informer:   factory.ForResource(gvr).Informer(),
+		}
+	}
+	factory.Start(cc.stopCh)
+
+	return cc, nil
+}
+
+// serverResource finds the resource served by the member cluster, nil if the resource is not served
+func serverResource(client discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (*metav1.APIResource, error) {
+	resources, err := client.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for i := range resources.APIResources {
+		if resources.APIResources[i].Name == gvr.Resource {
+			return &resources.APIResources[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// lookup returns the cache of the resource in cluster, or nil if the resource is not cached or not synced yet
+func (c *Cache) lookup(cluster string, gvr schema.GroupVersionResource) (*clusterCache, *resourceCache) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	cc, exist := c.clusters[cluster]
+	if !exist {
+		return nil, nil
+	}
+	rc, exist := cc.resources[gvr]
+	if !exist || !rc.informer.HasSynced() {
+		return nil, nil
+	}
+	return cc, rc
+}
+
+// isReady returns the health of the cluster when the cache is synced
+func (c *Cache) isReady(cc *clusterCache) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return cc.ready
+}
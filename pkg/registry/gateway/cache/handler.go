@@ -0,0 +1,288 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversionscheme "k8s.io/apimachinery/pkg/apis/meta/internalversion/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	gatewayproxy "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/proxy"
+)
+
+// requestInfo is the resource read by a request to the member cluster
+type requestInfo struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+// Wrap returns a handler serving the reads of cached resources in cluster, other requests like
+// writes, watches and the reads requiring the latest data are passed to next
+func (c *Cache) Wrap(cluster, path string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if !c.serve(resp, req, cluster, path) {
+			next.ServeHTTP(resp, req)
+		}
+	})
+}
+
+// serve returns false if the request is not served from the cache
+func (c *Cache) serve(resp http.ResponseWriter, req *http.Request, cluster, path string) bool {
+	if req.Method != http.MethodGet || !acceptsJSON(req.Header.Get("Accept")) {
+		return false
+	}
+	info, ok := parsePath(path)
+	if !ok {
+		return false
+	}
+	cc, rc := c.lookup(cluster, info.gvr)
+	if cc == nil {
+		return false
+	}
+	// the namespaced resources are got by namespace, and the cluster scoped resources have no namespace
+	if info.namespace != "" && !rc.namespaced || info.name != "" && rc.namespaced && info.namespace == "" {
+		return false
+	}
+
+	options := &metav1.ListOptions{}
+	if err := metainternalversionscheme.ParameterCodec.DecodeParameters(req.URL.Query(), metav1.SchemeGroupVersion, options); err != nil {
+		return false
+	}
+	if options.Watch || options.Continue != "" {
+		return false
+	}
+
+	stale, ok := c.servable(cc, rc, info, options)
+	if !ok {
+		return false
+	}
+
+	var fieldSelector fields.Selector
+	if options.FieldSelector != "" {
+		selector, err := fields.ParseSelector(options.FieldSelector)
+		if err != nil || !supportedFieldSelector(selector) {
+			return false
+		}
+		fieldSelector = selector
+	}
+	labelSelector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return false
+	}
+
+	requester, exist := request.UserFrom(req.Context())
+	if !exist {
+		return false
+	}
+	verb := "list"
+	if info.name != "" {
+		verb = "get"
+	}
+	allowed, reason, err := cc.authorizer.authorize(req.Context(), requester, &authorizationv1.ResourceAttributes{
+		Namespace: info.namespace,
+		Verb:      verb,
+		Group:     info.gvr.Group,
+		Version:   info.gvr.Version,
+		Resource:  info.gvr.Resource,
+		Name:      info.name,
+	})
+	if err != nil {
+		klog.V(4).InfoS("unable to authorize the read from cache", "cluster", cluster, "user", requester.GetName(), "reason", err.Error())
+		return false
+	}
+	if !allowed {
+		gatewayproxy.WriteError(resp, apierrors.NewForbidden(info.gvr.GroupResource(), info.name, fmt.Errorf("%s", reason)))
+		return true
+	}
+
+	if stale {
+		resp.Header().Add("Warning", fmt.Sprintf(`299 - "cluster %s is not ready, served from the cache"`, cluster))
+	}
+
+	var obj runtime.Object
+	if info.name != "" {
+		key := info.name
+		if info.namespace != "" {
+			key = info.namespace + "/" + info.name
+		}
+		item, exist, err := rc.informer.GetStore().GetByKey(key)
+		if err != nil {
+			gatewayproxy.WriteError(resp, err)
+			return true
+		}
+		if !exist {
+			gatewayproxy.WriteError(resp, apierrors.NewNotFound(info.gvr.GroupResource(), info.name))
+			return true
+		}
+		obj = item.(*unstructured.Unstructured)
+	} else {
+		obj = list(rc, info, labelSelector, fieldSelector)
+	}
+
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, obj)
+	if err != nil {
+		gatewayproxy.WriteError(resp, err)
+		return true
+	}
+	resp.Header().Set("Content-Type", runtime.ContentTypeJSON)
+	resp.WriteHeader(http.StatusOK)
+	_, _ = resp.Write(data)
+	return true
+}
+
+// servable returns whether the resourceVersion semantics of request are satisfied by the cache,
+// stale is true if the latest data is required but the member cluster is not ready
+func (c *Cache) servable(cc *clusterCache, rc *resourceCache, info requestInfo, options *metav1.ListOptions) (stale bool, ok bool) {
+	switch {
+	case options.ResourceVersion == "":
+		// the latest data is read from the member cluster, unless it is unreachable
+		if c.isReady(cc) {
+			return false, false
+		}
+		return true, true
+	case options.ResourceVersion == "0":
+		return false, true
+	case options.ResourceVersionMatch == metav1.ResourceVersionMatchExact,
+		// the resourceVersion of paginated lists is exact for legacy clients
+		info.name == "" && options.ResourceVersionMatch == "" && options.Limit > 0:
+		return false, false
+	}
+
+	expected, err := strconv.ParseUint(options.ResourceVersion, 10, 64)
+	if err != nil {
+		return false, false
+	}
+	synced, err := strconv.ParseUint(rc.informer.LastSyncResourceVersion(), 10, 64)
+	if err != nil {
+		return false, false
+	}
+	return false, synced >= expected
+}
+
+// list returns the cached objects matching the selectors, the limit is ignored as the watch cache of apiserver
+func list(rc *resourceCache, info requestInfo, labelSelector labels.Selector, fieldSelector fields.Selector) *unstructured.UnstructuredList {
+	var items []interface{}
+	if info.namespace != "" {
+		items, _ = rc.informer.GetIndexer().ByIndex(toolscache.NamespaceIndex, info.namespace)
+	} else {
+		items = rc.informer.GetStore().List()
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion(info.gvr.GroupVersion().String())
+	list.SetKind(rc.kind + "List")
+	list.SetResourceVersion(rc.informer.LastSyncResourceVersion())
+	for _, item := range items {
+		obj := item.(*unstructured.Unstructured)
+		if !labelSelector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		if fieldSelector != nil && !fieldSelector.Matches(fields.Set{"metadata.name": obj.GetName(), "metadata.namespace": obj.GetNamespace()}) {
+			continue
+		}
+		list.Items = append(list.Items, *obj)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].GetNamespace() != list.Items[j].GetNamespace() {
+			return list.Items[i].GetNamespace() < list.Items[j].GetNamespace()
+		}
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+	return list
+}
+
+// supportedFieldSelector returns true if the selector only requires the fields of metadata,
+// other fields are specific to resources and evaluated by the member cluster
+func supportedFieldSelector(selector fields.Selector) bool {
+	for _, requirement := range selector.Requirements() {
+		if requirement.Field != "metadata.name" && requirement.Field != "metadata.namespace" {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePath parses the path of resource requests, e.g. /api/v1/namespaces/default/pods/foo, /apis/apps/v1/deployments
+func parsePath(path string) (requestInfo, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	info := requestInfo{}
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		info.gvr.Version = parts[1]
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		info.gvr.Group = parts[1]
+		info.gvr.Version = parts[2]
+		parts = parts[3:]
+	default:
+		return info, false
+	}
+
+	// namespaces/{namespace} is the scope unless the namespaces are requested
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		info.namespace = parts[1]
+		parts = parts[2:]
+	}
+
+	switch len(parts) {
+	case 1:
+		info.gvr.Resource = parts[0]
+		return info, info.gvr.Resource != ""
+	case 2:
+		info.gvr.Resource = parts[0]
+		info.name = parts[1]
+		return info, info.gvr.Resource != "" && info.name != ""
+	default:
+		// subresources are always passed to the member cluster
+		return info, false
+	}
+}
+
+// acceptsJSON returns true if the objects could be responded in JSON, Table and protobuf only requests are not served
+func acceptsJSON(accept string) bool {
+	if accept == "" {
+		return true
+	}
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		if (mediaType == runtime.ContentTypeJSON || mediaType == "*/*" || mediaType == "application/*") && params["as"] == "" {
+			return true
+		}
+	}
+	return false
+}
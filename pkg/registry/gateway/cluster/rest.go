@@ -21,23 +21,38 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"path"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/registry/rest"
+	restclient "k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
 	"github.com/multi-cluster-platform/mcp/pkg/apis/gateway/v1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	gatewaycache "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/cache"
+	gatewayfanout "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/fanout"
+	gatewayproxy "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/proxy"
 )
 
 // REST implements a RESTStorage for Cluster API
-type REST struct{}
+type REST struct {
+	client client.Reader
+	// cache serves the reads of cached resources, nil if the cache is disabled
+	cache *gatewaycache.Cache
+}
 
 var _ rest.Connecter = &REST{}
-var _ rest.Redirector = &REST{}
 
 // NewREST returns a RESTStorage object that will work against API services.
-func NewREST() *REST {
-	return &REST{}
+func NewREST(client client.Reader, cache *gatewaycache.Cache) *REST {
+	return &REST{
+		client: client,
+		cache:  cache,
+	}
 }
 
 func (r *REST) NamespaceScoped() bool {
@@ -64,25 +79,61 @@ func (r *REST) Connect(ctx context.Context, id string, obj runtime.Object, respo
 	if !ok {
 		return nil, fmt.Errorf("invalid options object: %#v", obj)
 	}
-	klog.InfoS("handle for cluster rest", "id", id, "cluster.name", cluster.Name, "cluster.path", cluster.Path)
-
-	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
-		//user, exist := request.UserFrom(req.Context())
-		//if !exist {
-		//	responsewriters.InternalError(resp, req, errors.New("no user found for request"))
-		//	return
-		//}
-		//req.Header.Set(authenticationv1.ImpersonateUserHeader, requester.GetName())
-		//for _, group := range requester.GetGroups() {
-		//	if !skipGroup(group) {
-		//		req.Header.Add(authenticationv1.ImpersonateGroupHeader, group)
-		//	}
-		//}
-		//req.Header.Set("Authorization", fmt.Sprintf("bearer %s", impersonateToken))
-	}), nil
+	klog.V(4).InfoS("handle for cluster rest", "id", id, "cluster.name", cluster.Name, "cluster.path", cluster.Path)
+
+	// e.g. /clusters/*/api/v1/pods or /clusters/cluster1,cluster2/api/v1/pods
+	if gatewayfanout.IsFanout(id) {
+		return gatewayfanout.NewHandler(r.client, id, cluster.Path), nil
+	}
+
+	handler, err := r.proxyHandler(ctx, id, cluster.Path, responder)
+	if r.cache == nil {
+		return handler, err
+	}
+	if err != nil {
+		if !apierrors.IsServiceUnavailable(err) {
+			return nil, err
+		}
+		// the reads of cached resources are still served when the cluster is unavailable
+		handler = http.HandlerFunc(func(resp http.ResponseWriter, _ *http.Request) {
+			gatewayproxy.WriteError(resp, err)
+		})
+	}
+	return r.cache.Wrap(id, cluster.Path, handler), nil
 }
 
-// ResourceLocation returns url for resource redirect to
-func (r *REST) ResourceLocation(ctx context.Context, id string) (remoteLocation *url.URL, transport http.RoundTripper, err error) {
-	return nil, nil, nil
+// proxyHandler returns the handler proxying requests to the path of member cluster
+func (r *REST) proxyHandler(ctx context.Context, id, clusterPath string, responder rest.Responder) (http.Handler, error) {
+	config, err := r.clusterConfig(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q for cluster %s: %v", config.Host, id, err)
+	}
+	location.Path = path.Join(location.Path, clusterPath)
+
+	return gatewayproxy.NewHandler(location, config, responder)
+}
+
+// clusterConfig builds the rest config of member cluster from the registered Cluster
+func (r *REST) clusterConfig(ctx context.Context, name string) (*restclient.Config, error) {
+	cluster := &clusterv1alpha1.Cluster{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: name}, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, apierrors.NewNotFound(v1.Resource("clusters"), name)
+		}
+		return nil, err
+	}
+	if err := clusterutil.CheckAvailable(cluster); err != nil {
+		return nil, err
+	}
+
+	config, err := clusterutil.RESTConfig(ctx, r.client, cluster)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(err.Error())
+	}
+	return config, nil
 }
@@ -0,0 +1,440 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fanout
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	gatewayproxy "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/proxy"
+)
+
+const (
+	// AllClusters is the cluster id selecting all registered clusters
+	AllClusters = "*"
+
+	// ClusterAnnotation is set on each item of the merged results, with the name of its source cluster
+	ClusterAnnotation = "gateway.mcp.io/cluster"
+
+	// clusterSelectorParam is the query parameter selecting the clusters by labels, it is not passed to clusters
+	clusterSelectorParam = "clusterSelector"
+)
+
+// IsFanout returns true if the cluster id selects multiple clusters, which is * for all clusters,
+// or the cluster names separated by comma. Neither of them is a valid cluster name
+func IsFanout(id string) bool {
+	return id == AllClusters || strings.Contains(id, ",")
+}
+
+// NewHandler returns a handler which lists or watches the path from the selected clusters concurrently,
+// the results are merged into one list or one stream of watch events
+func NewHandler(reader client.Reader, id string, resourcePath string) http.Handler {
+	h := &handler{
+		reader: reader,
+		path:   resourcePath,
+	}
+	if id != AllClusters {
+		for _, name := range strings.Split(id, ",") {
+			if name != "" {
+				h.names = append(h.names, name)
+			}
+		}
+	}
+	return h
+}
+
+type handler struct {
+	reader client.Reader
+	// names is the selected clusters, all clusters are selected if empty
+	names []string
+	path  string
+}
+
+// clusterTarget is the config of a selected cluster
+type clusterTarget struct {
+	name   string
+	config *restclient.Config
+}
+
+func (h *handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		gatewayproxy.WriteError(resp, apierrors.NewMethodNotSupported(clusterv1alpha1.Resource("clusters"), req.Method))
+		return
+	}
+
+	requester, exist := request.UserFrom(req.Context())
+	if !exist {
+		gatewayproxy.WriteError(resp, apierrors.NewInternalError(errors.New("no user found for request")))
+		return
+	}
+
+	query := req.URL.Query()
+	selector, err := labels.Parse(query.Get(clusterSelectorParam))
+	if err != nil {
+		gatewayproxy.WriteError(resp, apierrors.NewBadRequest(fmt.Sprintf("invalid %s: %v", clusterSelectorParam, err)))
+		return
+	}
+	query.Del(clusterSelectorParam)
+
+	targets, unavailable, err := h.selectClusters(req.Context(), selector)
+	if err != nil {
+		gatewayproxy.WriteError(resp, err)
+		return
+	}
+	// the unavailable clusters are skipped, and reported in the Warning headers
+	for _, reason := range unavailable {
+		if warning, err := utilnet.NewWarningHeader(299, "-", reason.Error()); err == nil {
+			resp.Header().Add("Warning", warning)
+		}
+	}
+	for _, target := range targets {
+		target.config.Impersonate = gatewayproxy.ImpersonationConfig(requester)
+	}
+
+	// the merged list is not paginated
+	query.Del("limit")
+	query.Del("continue")
+	resourceVersions := decodeResourceVersion(query.Get("resourceVersion"))
+	query.Del("resourceVersion")
+
+	if watch := query.Get("watch"); watch == "true" || watch == "1" {
+		h.serveWatch(resp, req, targets, query, resourceVersions)
+		return
+	}
+	h.serveList(resp, req, targets, query, resourceVersions)
+}
+
+// selectClusters returns the clusters listed in the id and matching the selector, and the errors of the ones
+// which are not available
+func (h *handler) selectClusters(ctx context.Context, selector labels.Selector) ([]*clusterTarget, []error, error) {
+	var clusters []clusterv1alpha1.Cluster
+	if len(h.names) == 0 {
+		clusterList := &clusterv1alpha1.ClusterList{}
+		if err := h.reader.List(ctx, clusterList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, nil, apierrors.NewInternalError(err)
+		}
+		clusters = clusterList.Items
+	} else {
+		for _, name := range h.names {
+			cluster := clusterv1alpha1.Cluster{}
+			if err := h.reader.Get(ctx, client.ObjectKey{Name: name}, &cluster); err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, nil, apierrors.NewNotFound(clusterv1alpha1.Resource("clusters"), name)
+				}
+				return nil, nil, apierrors.NewInternalError(err)
+			}
+			if selector.Matches(labels.Set(cluster.Labels)) {
+				clusters = append(clusters, cluster)
+			}
+		}
+	}
+
+	targets := make([]*clusterTarget, 0, len(clusters))
+	var unavailable []error
+	for i := range clusters {
+		if err := clusterutil.CheckAvailable(&clusters[i]); err != nil {
+			unavailable = append(unavailable, err)
+			continue
+		}
+		config, err := clusterutil.RESTConfig(ctx, h.reader, &clusters[i])
+		if err != nil {
+			return nil, nil, apierrors.NewServiceUnavailable(fmt.Sprintf("cluster %s: %v", clusters[i].Name, err))
+		}
+		targets = append(targets, &clusterTarget{name: clusters[i].Name, config: config})
+	}
+	return targets, unavailable, nil
+}
+
+// serveList lists from all the clusters concurrently, any failure of cluster fails the request
+func (h *handler) serveList(resp http.ResponseWriter, req *http.Request, targets []*clusterTarget, query url.Values, resourceVersions resourceVersions) {
+	results := make([]*unstructured.UnstructuredList, len(targets))
+	errs := make([]error, len(targets))
+
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = h.list(req.Context(), targets[i], withResourceVersion(query, resourceVersions.get(targets[i].name)))
+		}(i)
+	}
+	wg.Wait()
+
+	merged := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+	merged.SetAPIVersion("v1")
+	merged.SetKind("List")
+	current := make(map[string]string, len(targets))
+	for i, result := range results {
+		if errs[i] != nil {
+			gatewayproxy.WriteError(resp, errs[i])
+			return
+		}
+		if i == 0 {
+			merged.SetAPIVersion(result.GetAPIVersion())
+			merged.SetKind(result.GetKind())
+		}
+		current[targets[i].name] = result.GetResourceVersion()
+		for _, item := range result.Items {
+			setCluster(&item, targets[i].name)
+			merged.Items = append(merged.Items, item)
+		}
+	}
+	merged.SetResourceVersion(encodeResourceVersion(current))
+
+	data, err := merged.MarshalJSON()
+	if err != nil {
+		gatewayproxy.WriteError(resp, apierrors.NewInternalError(err))
+		return
+	}
+	resp.Header().Set("Content-Type", runtime.ContentTypeJSON)
+	resp.WriteHeader(http.StatusOK)
+	_, _ = resp.Write(data)
+}
+
+// list gets the path from the cluster, a single object is returned as a list of one item
+func (h *handler) list(ctx context.Context, target *clusterTarget, query url.Values) (*unstructured.UnstructuredList, error) {
+	body, err := h.do(ctx, target, query)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("cluster %s: %v", target.name, err))
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("cluster %s: %v", target.name, err))
+	}
+	if obj.IsList() {
+		return obj.ToList()
+	}
+	return &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "List", "metadata": map[string]interface{}{}},
+		Items:  []unstructured.Unstructured{*obj},
+	}, nil
+}
+
+// serveWatch multiplexes the watch events of all the clusters, the watch is closed when any of them ends
+func (h *handler) serveWatch(resp http.ResponseWriter, req *http.Request, targets []*clusterTarget, query url.Values, resourceVersions resourceVersions) {
+	flusher, ok := resp.(http.Flusher)
+	if !ok {
+		gatewayproxy.WriteError(resp, apierrors.NewInternalError(errors.New("streaming is not supported")))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	// open all the watches before writing the response, so that the failure is returned as status
+	bodies := make([]io.ReadCloser, len(targets))
+	for i, target := range targets {
+		body, err := h.do(ctx, target, withResourceVersion(query, resourceVersions.get(target.name)))
+		if err != nil {
+			for _, opened := range bodies[:i] {
+				opened.Close()
+			}
+			gatewayproxy.WriteError(resp, err)
+			return
+		}
+		bodies[i] = body
+	}
+
+	type clusterEvent struct {
+		cluster string
+		event   metav1.WatchEvent
+	}
+	events := make(chan clusterEvent)
+	for i := range targets {
+		go func(cluster string, body io.ReadCloser) {
+			defer cancel()
+			defer body.Close()
+
+			decoder := json.NewDecoder(body)
+			for {
+				var event metav1.WatchEvent
+				if err := decoder.Decode(&event); err != nil {
+					if err != io.EOF && ctx.Err() == nil {
+						klog.V(1).InfoS("watch of cluster is closed", "cluster", cluster, "err", err)
+					}
+					return
+				}
+				select {
+				case events <- clusterEvent{cluster: cluster, event: event}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(targets[i].name, bodies[i])
+	}
+
+	resp.Header().Set("Content-Type", runtime.ContentTypeJSON)
+	resp.Header().Set("Transfer-Encoding", "chunked")
+	resp.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// the resource version of each event is the combination of the latest ones of all the clusters,
+	// so the watch can be resumed from any event
+	current := make(map[string]string, len(targets))
+	for name, resourceVersion := range resourceVersions.clusters {
+		current[name] = resourceVersion
+	}
+	encoder := json.NewEncoder(resp)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			if e.event.Type != "ERROR" {
+				obj := &unstructured.Unstructured{}
+				if err := obj.UnmarshalJSON(e.event.Object.Raw); err != nil {
+					klog.V(1).InfoS("unable to decode watch event", "cluster", e.cluster, "err", err)
+					return
+				}
+				current[e.cluster] = obj.GetResourceVersion()
+				obj.SetResourceVersion(encodeResourceVersion(current))
+				setCluster(obj, e.cluster)
+
+				data, err := obj.MarshalJSON()
+				if err != nil {
+					return
+				}
+				e.event.Object = runtime.RawExtension{Raw: data}
+			}
+
+			if err := encoder.Encode(&e.event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// do requests the path of cluster, the response body is returned if the status is OK
+func (h *handler) do(ctx context.Context, target *clusterTarget, query url.Values) (io.ReadCloser, error) {
+	transport, err := restclient.TransportFor(target.config)
+	if err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("cluster %s: %v", target.name, err))
+	}
+
+	location, err := url.Parse(target.config.Host)
+	if err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("invalid host %q for cluster %s: %v", target.config.Host, target.name, err))
+	}
+	location.Path = path.Join(location.Path, h.path)
+	location.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location.String(), nil)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	req.Header.Set("Accept", runtime.ContentTypeJSON)
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, apierrors.NewServiceUnavailable(fmt.Sprintf("cluster %s: %v", target.name, err))
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp.Body, nil
+	}
+	defer resp.Body.Close()
+
+	// the status of cluster is returned as is, with the cluster name in message
+	data, _ := io.ReadAll(resp.Body)
+	status := &metav1.Status{}
+	if err := json.Unmarshal(data, status); err != nil || status.Kind != "Status" {
+		return nil, apierrors.NewGenericServerResponse(resp.StatusCode, http.MethodGet, clusterv1alpha1.Resource("clusters"), target.name, string(data), 0, true)
+	}
+	status.Message = fmt.Sprintf("cluster %s: %s", target.name, status.Message)
+	return nil, &apierrors.StatusError{ErrStatus: *status}
+}
+
+func setCluster(obj *unstructured.Unstructured, cluster string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[ClusterAnnotation] = cluster
+	obj.SetAnnotations(annotations)
+}
+
+func withResourceVersion(query url.Values, resourceVersion string) url.Values {
+	result := make(url.Values, len(query)+1)
+	for key, values := range query {
+		result[key] = values
+	}
+	if resourceVersion != "" {
+		result.Set("resourceVersion", resourceVersion)
+	}
+	return result
+}
+
+// encodeResourceVersion combines the resource versions of clusters into one
+func encodeResourceVersion(resourceVersions map[string]string) string {
+	data, _ := json.Marshal(resourceVersions)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// resourceVersions is the resource versions requested for clusters
+type resourceVersions struct {
+	clusters map[string]string
+	// common is used for the clusters not in the combined resource version
+	common string
+}
+
+func (r resourceVersions) get(cluster string) string {
+	if resourceVersion, ok := r.clusters[cluster]; ok {
+		return resourceVersion
+	}
+	return r.common
+}
+
+// decodeResourceVersion splits the combined resource version into the ones of clusters,
+// the resource version which is not combined, e.g. 0, is used for all the clusters
+func decodeResourceVersion(resourceVersion string) resourceVersions {
+	clusters := map[string]string{}
+	if resourceVersion != "" {
+		if data, err := base64.RawURLEncoding.DecodeString(resourceVersion); err == nil && json.Unmarshal(data, &clusters) == nil {
+			return resourceVersions{clusters: clusters}
+		}
+	}
+	return resourceVersions{common: resourceVersion}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/proxy"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	restclient "k8s.io/client-go/rest"
+)
+
+// NewHandler returns a handler proxying requests to the location with the platform credential in config,
+// the authenticated caller is impersonated so that RBAC is enforced by the target apiserver
+func NewHandler(location *url.URL, config *restclient.Config, responder rest.Responder) (http.Handler, error) {
+	transport, err := restclient.TransportFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create transport for %s: %v", config.Host, err)
+	}
+
+	// the upgrade aware handler flushes periodically, so chunked watch responses are streamed back
+	handler := proxy.NewUpgradeAwareHandler(location, transport, false, false, proxy.NewErrorResponder(responder))
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		requester, exist := request.UserFrom(req.Context())
+		if !exist {
+			responsewriters.InternalError(resp, req, errors.New("no user found for request"))
+			return
+		}
+
+		// never pass the caller credential to the target apiserver, the bearer token is set
+		// here instead of the transport to cover upgrade requests as well
+		req.Header.Del("Authorization")
+		if config.BearerToken != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", config.BearerToken))
+		}
+		setImpersonateHeaders(req.Header, requester)
+
+		handler.ServeHTTP(resp, req)
+	}), nil
+}
+
+// WriteError writes the error as Status, which is understood by the clients of apiserver
+func WriteError(resp http.ResponseWriter, err error) {
+	var status metav1.Status
+	if apiStatus, ok := err.(apierrors.APIStatus); ok {
+		status = apiStatus.Status()
+	} else {
+		status = apierrors.NewInternalError(err).Status()
+	}
+	status.Kind = "Status"
+	status.APIVersion = "v1"
+	if status.Code == 0 {
+		status.Code = http.StatusInternalServerError
+	}
+
+	resp.Header().Set("Content-Type", runtime.ContentTypeJSON)
+	resp.WriteHeader(int(status.Code))
+	_ = json.NewEncoder(resp).Encode(&status)
+}
+
+// setImpersonateHeaders replaces any impersonation headers from the caller with the requester
+func setImpersonateHeaders(header http.Header, requester user.Info) {
+	for key := range header {
+		if strings.HasPrefix(key, "Impersonate-") {
+			header.Del(key)
+		}
+	}
+
+	header.Set(authenticationv1.ImpersonateUserHeader, requester.GetName())
+	if uid := requester.GetUID(); uid != "" {
+		header.Set(authenticationv1.ImpersonateUIDHeader, uid)
+	}
+	for _, group := range requester.GetGroups() {
+		if !skipGroup(group) {
+			header.Add(authenticationv1.ImpersonateGroupHeader, group)
+		}
+	}
+	for key, values := range requester.GetExtra() {
+		for _, value := range values {
+			header.Add(authenticationv1.ImpersonateUserExtraHeaderPrefix+headerKeyEscape(key), value)
+		}
+	}
+}
+
+// ImpersonationConfig returns the impersonation of requester for the clients built from rest config
+func ImpersonationConfig(requester user.Info) restclient.ImpersonationConfig {
+	config := restclient.ImpersonationConfig{
+		UserName: requester.GetName(),
+		UID:      requester.GetUID(),
+		Extra:    requester.GetExtra(),
+	}
+	for _, group := range requester.GetGroups() {
+		if !skipGroup(group) {
+			config.Groups = append(config.Groups, group)
+		}
+	}
+	return config
+}
+
+// skipGroup skips the virtual groups which are added by the target apiserver itself
+func skipGroup(group string) bool {
+	switch group {
+	case user.AllAuthenticated, user.AllUnauthenticated:
+		return true
+	default:
+		return false
+	}
+}
+
+// headerKeyEscape percent-encodes the bytes which are not allowed in header keys, same as client-go
+func headerKeyEscape(key string) string {
+	buf := strings.Builder{}
+	for i := 0; i < len(key); i++ {
+		b := key[i]
+		if !legalHeaderByte(b) || b == '%' {
+			fmt.Fprintf(&buf, "%%%02X", b)
+			continue
+		}
+		buf.WriteByte(b)
+	}
+	return buf.String()
+}
+
+func legalHeaderByte(b byte) bool {
+	if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", b) >= 0
+}
@@ -20,22 +20,31 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/registry/rest"
+	restclient "k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
 	"github.com/multi-cluster-platform/mcp/pkg/apis/gateway/v1"
+	gatewayproxy "github.com/multi-cluster-platform/mcp/pkg/registry/gateway/proxy"
 )
 
 // REST implements a RESTStorage for Proxies API
-type REST struct{}
+type REST struct {
+	// config is used to connect to the hub cluster
+	config *restclient.Config
+}
 
 var _ rest.Connecter = &REST{}
 
 // NewREST returns a RESTStorage object that will work against API services.
-func NewREST() *REST {
-	return &REST{}
+func NewREST(config *restclient.Config) *REST {
+	return &REST{
+		config: config,
+	}
 }
 
 func (r *REST) NamespaceScoped() bool {
@@ -62,21 +71,14 @@ func (r *REST) Connect(ctx context.Context, id string, obj runtime.Object, respo
 	if !ok {
 		return nil, fmt.Errorf("invalid options object: %#v", obj)
 	}
-	klog.InfoS("handle for shadow rest", "id", id, "shadow.path", shadow.Path)
-
-	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
-		//requester, exist := request.UserFrom(req.Context())
-		//if !exist {
-		//	responsewriters.InternalError(resp, req, errors.New("no user found for request"))
-		//	return
-		//}
-		//req.Header.Set(authenticationv1.ImpersonateUserHeader, requester.GetName())
-		//for _, group := range requester.GetGroups() {
-		//	if !skipGroup(group) {
-		//		req.Header.Add(authenticationv1.ImpersonateGroupHeader, group)
-		//	}
-		//}
-		//
-		//req.Header.Set("Authorization", fmt.Sprintf("bearer %s", impersonateToken))
-	}), nil
+	klog.V(4).InfoS("handle for shadow rest", "id", id, "shadow.path", shadow.Path)
+
+	location, err := url.Parse(r.config.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q for hub cluster: %v", r.config.Host, err)
+	}
+	// the id is the first segment of the path, e.g. api for /shadow/api/v1/nodes
+	location.Path = path.Join(location.Path, id, shadow.Path)
+
+	return gatewayproxy.NewHandler(location, r.config, responder)
 }
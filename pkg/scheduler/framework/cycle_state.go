@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sync"
+)
+
+// StateData is a generic type for arbitrary data stored in CycleState
+type StateData interface {
+	// Clone is an interface to make a copy of StateData
+	Clone() StateData
+}
+
+// StateKey is the type of keys stored in CycleState
+type StateKey string
+
+// CycleState provides a mechanism for plugins to store and retrieve arbitrary data
+// in the scheduling cycle of one Deployable
+type CycleState struct {
+	lock    sync.RWMutex
+	storage map[StateKey]StateData
+}
+
+// NewCycleState initializes a new CycleState and returns its pointer
+func NewCycleState() *CycleState {
+	return &CycleState{
+		storage: make(map[StateKey]StateData),
+	}
+}
+
+// Read retrieves data with the given key from CycleState
+func (c *CycleState) Read(key StateKey) (StateData, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if v, ok := c.storage[key]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%s not found in cycle state", key)
+}
+
+// Write stores the given key and value in CycleState
+func (c *CycleState) Write(key StateKey, val StateData) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.storage[key] = val
+}
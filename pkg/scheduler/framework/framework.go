@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/profile"
+)
+
+// frameworkImpl is the component responsible for initializing and running scheduler plugins
+type frameworkImpl struct {
+	clientReader client.Reader
+
+	preFilterPlugins []PreFilterPlugin
+	filterPlugins    []FilterPlugin
+	scorePlugins     []ScorePlugin
+	reservePlugins   []ReservePlugin
+
+	scorePluginWeight map[string]int64
+}
+
+var _ Framework = &frameworkImpl{}
+
+// NewFramework initializes the plugins enabled in profile with the registry
+func NewFramework(registry Registry, p *profile.Profile, clientReader client.Reader) (Framework, error) {
+	f := &frameworkImpl{
+		clientReader:      clientReader,
+		scorePluginWeight: make(map[string]int64),
+	}
+
+	pluginArgs := make(map[string]runtime.RawExtension, len(p.PluginConfig))
+	for _, config := range p.PluginConfig {
+		pluginArgs[config.Name] = config.Args
+	}
+
+	// plugins are initialized once even if enabled at multiple extension points
+	plugins := make(map[string]Plugin)
+	getPlugin := func(name string) (Plugin, error) {
+		if plugin, ok := plugins[name]; ok {
+			return plugin, nil
+		}
+		factory, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("plugin %q does not exist", name)
+		}
+		plugin, err := factory(pluginArgs[name], f)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize plugin %q: %v", name, err)
+		}
+		plugins[name] = plugin
+		return plugin, nil
+	}
+
+	for _, config := range p.Plugins.PreFilter.Enabled {
+		plugin, err := getPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		preFilterPlugin, ok := plugin.(PreFilterPlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %q does not extend PreFilter plugin", config.Name)
+		}
+		f.preFilterPlugins = append(f.preFilterPlugins, preFilterPlugin)
+	}
+
+	for _, config := range p.Plugins.Filter.Enabled {
+		plugin, err := getPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		filterPlugin, ok := plugin.(FilterPlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %q does not extend Filter plugin", config.Name)
+		}
+		f.filterPlugins = append(f.filterPlugins, filterPlugin)
+	}
+
+	for _, config := range p.Plugins.Score.Enabled {
+		plugin, err := getPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		scorePlugin, ok := plugin.(ScorePlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %q does not extend Score plugin", config.Name)
+		}
+		weight := int64(config.Weight)
+		if weight == 0 {
+			weight = 1
+		}
+		f.scorePlugins = append(f.scorePlugins, scorePlugin)
+		f.scorePluginWeight[config.Name] = weight
+	}
+
+	for _, config := range p.Plugins.Reserve.Enabled {
+		plugin, err := getPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		reservePlugin, ok := plugin.(ReservePlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %q does not extend Reserve plugin", config.Name)
+		}
+		f.reservePlugins = append(f.reservePlugins, reservePlugin)
+	}
+
+	return f, nil
+}
+
+// ClientReader returns the reader of the hub cluster
+func (f *frameworkImpl) ClientReader() client.Reader {
+	return f.clientReader
+}
+
+// RunPreFilterPlugins runs the set of configured PreFilter plugins
+func (f *frameworkImpl) RunPreFilterPlugins(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable) *Status {
+	for _, plugin := range f.preFilterPlugins {
+		if status := plugin.PreFilter(ctx, state, deployable); !status.IsSuccess() {
+			return status.WithPlugin(plugin.Name())
+		}
+	}
+	return nil
+}
+
+// RunFilterPlugins runs the set of configured Filter plugins for the cluster
+func (f *frameworkImpl) RunFilterPlugins(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) *Status {
+	for _, plugin := range f.filterPlugins {
+		if status := plugin.Filter(ctx, state, deployable, cluster); !status.IsSuccess() {
+			return status.WithPlugin(plugin.Name())
+		}
+	}
+	return nil
+}
+
+// RunScorePlugins runs the set of configured Score plugins and returns the weighted sum of normalized scores
+func (f *frameworkImpl) RunScorePlugins(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, clusters []*clusterv1alpha1.Cluster) (ClusterScoreList, *Status) {
+	result := make(ClusterScoreList, len(clusters))
+	for i, cluster := range clusters {
+		result[i] = ClusterScore{Name: cluster.Name}
+	}
+
+	for _, plugin := range f.scorePlugins {
+		scores := make(ClusterScoreList, len(clusters))
+		for i, cluster := range clusters {
+			score, status := plugin.Score(ctx, state, deployable, cluster)
+			if !status.IsSuccess() {
+				return nil, status.WithPlugin(plugin.Name())
+			}
+			scores[i] = ClusterScore{Name: cluster.Name, Score: score}
+		}
+
+		if extensions := plugin.ScoreExtensions(); extensions != nil {
+			if status := extensions.NormalizeScore(ctx, state, deployable, scores); !status.IsSuccess() {
+				return nil, status.WithPlugin(plugin.Name())
+			}
+		}
+
+		weight := f.scorePluginWeight[plugin.Name()]
+		for i, score := range scores {
+			if score.Score > MaxClusterScore || score.Score < MinClusterScore {
+				return nil, AsStatus(fmt.Errorf("plugin %q returns an invalid score %v for cluster %s", plugin.Name(), score.Score, score.Name))
+			}
+			result[i].Score += score.Score * weight
+		}
+	}
+
+	return result, nil
+}
+
+// RunReservePlugins runs the set of configured Reserve plugins
+func (f *frameworkImpl) RunReservePlugins(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, clusters []string) *Status {
+	for _, plugin := range f.reservePlugins {
+		if status := plugin.Reserve(ctx, state, deployable, clusters); !status.IsSuccess() {
+			return status.WithPlugin(plugin.Name())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+)
+
+// Code is the status code returned by plugins
+type Code int
+
+const (
+	// Success means the plugin ran correctly
+	Success Code = iota
+	// Error is used for internal plugin errors, the Deployable will be requeued
+	Error
+	// Unschedulable means the Deployable could not be placed to the cluster
+	Unschedulable
+)
+
+var codes = []string{"Success", "Error", "Unschedulable"}
+
+func (c Code) String() string {
+	if c < 0 || int(c) >= len(codes) {
+		return fmt.Sprintf("Code(%d)", int(c))
+	}
+	return codes[c]
+}
+
+const (
+	// MaxClusterScore is the maximum score a Score plugin is expected to return
+	MaxClusterScore int64 = 100
+	// MinClusterScore is the minimum score a Score plugin is expected to return
+	MinClusterScore int64 = 0
+)
+
+// Status indicates the result of running a plugin, a nil Status is considered as Success
+type Status struct {
+	code    Code
+	reasons []string
+	err     error
+	plugin  string
+}
+
+// NewStatus makes a Status out of the given arguments and returns its pointer
+func NewStatus(code Code, reasons ...string) *Status {
+	s := &Status{
+		code:    code,
+		reasons: reasons,
+	}
+	if code == Error {
+		s.err = errors.New(s.Message())
+	}
+	return s
+}
+
+// AsStatus wraps an error in a Status
+func AsStatus(err error) *Status {
+	return &Status{
+		code:    Error,
+		reasons: []string{err.Error()},
+		err:     err,
+	}
+}
+
+// Code returns code of the Status
+func (s *Status) Code() Code {
+	if s == nil {
+		return Success
+	}
+	return s.code
+}
+
+// Message returns a concatenated message on reasons of the Status
+func (s *Status) Message() string {
+	if s == nil {
+		return ""
+	}
+	return strings.Join(s.reasons, ", ")
+}
+
+// Reasons returns reasons of the Status
+func (s *Status) Reasons() []string {
+	if s == nil {
+		return nil
+	}
+	return s.reasons
+}
+
+// Plugin returns the name of plugin which returns the Status
+func (s *Status) Plugin() string {
+	if s == nil {
+		return ""
+	}
+	return s.plugin
+}
+
+// WithPlugin sets the name of plugin which returns the Status
+func (s *Status) WithPlugin(plugin string) *Status {
+	s.plugin = plugin
+	return s
+}
+
+// IsSuccess returns true if and only if the Status is nil or the code is Success
+func (s *Status) IsSuccess() bool {
+	return s.Code() == Success
+}
+
+// IsUnschedulable returns true if the code is Unschedulable
+func (s *Status) IsUnschedulable() bool {
+	return s.Code() == Unschedulable
+}
+
+// AsError returns nil if the status is a success, otherwise returns an error
+func (s *Status) AsError() error {
+	if s.IsSuccess() {
+		return nil
+	}
+	if s.err != nil {
+		return s.err
+	}
+	return errors.New(s.Message())
+}
+
+// ClusterScore is the score of a cluster
+type ClusterScore struct {
+	Name  string
+	Score int64
+}
+
+// ClusterScoreList declares a list of clusters and their scores
+type ClusterScoreList []ClusterScore
+
+// Plugin is the parent type for all the scheduling framework plugins
+type Plugin interface {
+	Name() string
+}
+
+// PreFilterPlugin is called at the beginning of the scheduling cycle
+type PreFilterPlugin interface {
+	Plugin
+	// PreFilter is called once for the Deployable before filtering, all PreFilter plugins must return
+	// success or the Deployable will be rejected
+	PreFilter(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable) *Status
+}
+
+// FilterPlugin is called to filter out the clusters which could not run the Deployable
+type FilterPlugin interface {
+	Plugin
+	// Filter is called for each registered cluster, all Filter plugins must return success
+	// or the cluster will be excluded
+	Filter(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) *Status
+}
+
+// ScorePlugin is called to rank the clusters passed the filtering phase
+type ScorePlugin interface {
+	Plugin
+	// Score is called for each filtered cluster, it returns an integer indicating the rank of the cluster
+	Score(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) (int64, *Status)
+	// ScoreExtensions returns a ScoreExtensions interface if it implements one, or nil if does not
+	ScoreExtensions() ScoreExtensions
+}
+
+// ScoreExtensions is an interface for Score extended functionality
+type ScoreExtensions interface {
+	// NormalizeScore is called for all cluster scores produced by the same plugin's Score method,
+	// the scores should be normalized into [MinClusterScore, MaxClusterScore]
+	NormalizeScore(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, scores ClusterScoreList) *Status
+}
+
+// ReservePlugin is called after the clusters are selected and before the decisions are bound
+type ReservePlugin interface {
+	Plugin
+	// Reserve is called with the selected clusters, ordered by score, a non-success Status rejects the Deployable
+	Reserve(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, clusters []string) *Status
+}
+
+// Handle provides data and services accessible to plugins
+type Handle interface {
+	// ClientReader returns the reader of the hub cluster
+	ClientReader() client.Reader
+}
+
+// Framework manages the set of plugins in use by the scheduler
+type Framework interface {
+	Handle
+
+	// RunPreFilterPlugins runs the set of configured PreFilter plugins
+	RunPreFilterPlugins(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable) *Status
+
+	// RunFilterPlugins runs the set of configured Filter plugins for the cluster
+	RunFilterPlugins(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) *Status
+
+	// RunScorePlugins runs the set of configured Score plugins and returns the weighted sum of normalized scores
+	RunScorePlugins(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, clusters []*clusterv1alpha1.Cluster) (ClusterScoreList, *Status)
+
+	// RunReservePlugins runs the set of configured Reserve plugins
+	RunReservePlugins(ctx context.Context, state *CycleState, deployable *appsv1alpha1.Deployable, clusters []string) *Status
+}
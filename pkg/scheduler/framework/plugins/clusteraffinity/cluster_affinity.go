@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusteraffinity
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/names"
+)
+
+// Name is the name of the plugin used in the plugin registry and configurations
+const Name = names.ClusterAffinity
+
+const stateKey framework.StateKey = Name
+
+// ClusterAffinity is a plugin that selects the clusters listed in Placement.ClusterNames,
+// or matching both Placement.ClusterSelector and Placement.ClaimSelector
+type ClusterAffinity struct {
+	handle framework.Handle
+}
+
+var _ framework.PreFilterPlugin = &ClusterAffinity{}
+var _ framework.FilterPlugin = &ClusterAffinity{}
+
+// preFilterState holds the parsed selectors of the Deployable
+type preFilterState struct {
+	clusterNames  map[string]struct{}
+	labelSelector labels.Selector
+	claimSelector labels.Selector
+}
+
+// Clone the prefilter state.
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+// New initializes a new plugin and returns it
+func New(_ runtime.RawExtension, handle framework.Handle) (framework.Plugin, error) {
+	return &ClusterAffinity{
+		handle: handle,
+	}, nil
+}
+
+// Name returns name of the plugin
+func (pl *ClusterAffinity) Name() string {
+	return Name
+}
+
+// PreFilter parses the selectors, and makes sure all the listed clusters are registered,
+// the Deployable is requeued until they are
+func (pl *ClusterAffinity) PreFilter(ctx context.Context, state *framework.CycleState, deployable *appsv1alpha1.Deployable) *framework.Status {
+	placement := deployable.Spec.Placement
+
+	s := &preFilterState{
+		clusterNames: make(map[string]struct{}, len(placement.ClusterNames)),
+	}
+	for _, clusterName := range placement.ClusterNames {
+		cluster := &clusterv1alpha1.Cluster{}
+		if err := pl.handle.ClientReader().Get(ctx, client.ObjectKey{Name: clusterName}, cluster); err != nil {
+			return framework.AsStatus(fmt.Errorf("unable to get Cluster %s: %v", clusterName, err))
+		}
+		s.clusterNames[clusterName] = struct{}{}
+	}
+
+	if placement.ClusterSelector != nil || placement.ClaimSelector != nil {
+		s.labelSelector = labels.Everything()
+		s.claimSelector = labels.Everything()
+	}
+	if placement.ClusterSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(placement.ClusterSelector)
+		if err != nil {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("invalid clusterSelector: %v", err))
+		}
+		s.labelSelector = selector
+	}
+	if placement.ClaimSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+			MatchExpressions: placement.ClaimSelector.MatchExpressions,
+		})
+		if err != nil {
+			return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("invalid claimSelector: %v", err))
+		}
+		s.claimSelector = selector
+	}
+
+	state.Write(stateKey, s)
+	return nil
+}
+
+// Filter checks if the cluster is listed or selected by the Deployable
+func (pl *ClusterAffinity) Filter(_ context.Context, state *framework.CycleState, _ *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) *framework.Status {
+	data, err := state.Read(stateKey)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	s := data.(*preFilterState)
+
+	if _, ok := s.clusterNames[cluster.Name]; ok {
+		return nil
+	}
+
+	if s.labelSelector == nil {
+		return framework.NewStatus(framework.Unschedulable, "cluster is not listed in clusterNames")
+	}
+	if !s.labelSelector.Matches(labels.Set(cluster.Labels)) {
+		return framework.NewStatus(framework.Unschedulable, "cluster does not match clusterSelector")
+	}
+
+	claims := make(labels.Set, len(cluster.Spec.ClusterClaims))
+	for _, claim := range cluster.Spec.ClusterClaims {
+		claims[claim.Name] = claim.Value
+	}
+	if !s.claimSelector.Matches(claims) {
+		return framework.NewStatus(framework.Unschedulable, "cluster does not match claimSelector")
+	}
+
+	return nil
+}
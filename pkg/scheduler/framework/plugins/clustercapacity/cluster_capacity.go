@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustercapacity
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/names"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/workload"
+)
+
+// Name is the name of the plugin used in the plugin registry and configurations
+const Name = names.ClusterCapacity
+
+const stateKey framework.StateKey = Name
+
+// ClusterCapacity is a plugin that estimates how many replicas of the workloads fit into the clusters by
+// their resource summaries, the clusters without enough resources are filtered out unless already decided,
+// and the clusters with more available replicas are preferred
+type ClusterCapacity struct {
+	handle framework.Handle
+}
+
+var _ framework.PreFilterPlugin = &ClusterCapacity{}
+var _ framework.FilterPlugin = &ClusterCapacity{}
+var _ framework.ScorePlugin = &ClusterCapacity{}
+var _ framework.ScoreExtensions = &ClusterCapacity{}
+
+// preFilterState holds the workloads of the Deployable
+type preFilterState struct {
+	workloads []workload.Workload
+}
+
+// Clone the prefilter state.
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+// New initializes a new plugin and returns it
+func New(_ runtime.RawExtension, handle framework.Handle) (framework.Plugin, error) {
+	return &ClusterCapacity{
+		handle: handle,
+	}, nil
+}
+
+// Name returns name of the plugin
+func (pl *ClusterCapacity) Name() string {
+	return Name
+}
+
+// PreFilter reads the replicas and pod requests of the workloads from the Manifests
+func (pl *ClusterCapacity) PreFilter(ctx context.Context, state *framework.CycleState, deployable *appsv1alpha1.Deployable) *framework.Status {
+	workloads, err := workload.List(ctx, pl.handle.ClientReader(), deployable)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return framework.NewStatus(framework.Unschedulable, err.Error())
+		}
+		return framework.AsStatus(fmt.Errorf("unable to read workloads: %v", err))
+	}
+	state.Write(stateKey, &preFilterState{workloads: workloads})
+	return nil
+}
+
+// Filter checks if the cluster runs the replicas of each workload, which are all the replicas if the replicas are
+// not divided, or at least one replica otherwise. The clusters not reporting resource summaries are not filtered.
+func (pl *ClusterCapacity) Filter(_ context.Context, state *framework.CycleState, deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) *framework.Status {
+	s, err := readState(state)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if cluster.Status.ResourceSummary == nil {
+		return nil
+	}
+	// the replicas running in the decided clusters are counted as allocated
+	for _, decision := range deployable.Status.PlacementDecisions {
+		if decision.Cluster == cluster.Name {
+			return nil
+		}
+	}
+
+	for _, w := range s.workloads {
+		required := int64(w.Replicas)
+		if deployable.Spec.Placement.ReplicaScheduling != nil && required > 1 {
+			required = 1
+		}
+		if available := clusterutil.AvailableReplicas(cluster, w.PodRequests); available < required {
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("insufficient resources for %s %s/%s, %d of %d replicas fit", w.Resource.Kind, w.Resource.Namespace, w.Resource.Name, available, required))
+		}
+	}
+	return nil
+}
+
+// Score returns the minimum ratio of available replicas to the desired replicas of the workloads
+func (pl *ClusterCapacity) Score(_ context.Context, state *framework.CycleState, _ *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) (int64, *framework.Status) {
+	s, err := readState(state)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+
+	score := framework.MaxClusterScore
+	for _, w := range s.workloads {
+		if w.Replicas == 0 {
+			continue
+		}
+		available := clusterutil.AvailableReplicas(cluster, w.PodRequests)
+		if ratio := available * framework.MaxClusterScore / int64(w.Replicas); ratio < score {
+			score = ratio
+		}
+	}
+	return score, nil
+}
+
+// ScoreExtensions of the Score plugin
+func (pl *ClusterCapacity) ScoreExtensions() framework.ScoreExtensions {
+	return pl
+}
+
+// NormalizeScore scales the scores so the cluster with the most available replicas has the highest score
+func (pl *ClusterCapacity) NormalizeScore(_ context.Context, _ *framework.CycleState, _ *appsv1alpha1.Deployable, scores framework.ClusterScoreList) *framework.Status {
+	var maxScore int64
+	for _, score := range scores {
+		if score.Score > maxScore {
+			maxScore = score.Score
+		}
+	}
+	if maxScore == 0 {
+		return nil
+	}
+
+	for i := range scores {
+		scores[i].Score = scores[i].Score * framework.MaxClusterScore / maxScore
+	}
+	return nil
+}
+
+func readState(state *framework.CycleState) (*preFilterState, error) {
+	data, err := state.Read(stateKey)
+	if err != nil {
+		return nil, err
+	}
+	return data.(*preFilterState), nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhealth
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/names"
+)
+
+// Name is the name of the plugin used in the plugin registry and configurations
+const Name = names.ClusterHealth
+
+// ClusterHealth is a plugin that filters out the clusters not ready, the clusters already in the
+// decisions are kept, so a Deployable is not moved away by a transient failure of cluster
+type ClusterHealth struct{}
+
+var _ framework.FilterPlugin = &ClusterHealth{}
+
+// New initializes a new plugin and returns it
+func New(_ runtime.RawExtension, _ framework.Handle) (framework.Plugin, error) {
+	return &ClusterHealth{}, nil
+}
+
+// Name returns name of the plugin
+func (pl *ClusterHealth) Name() string {
+	return Name
+}
+
+// Filter checks if the cluster is ready or already decided
+func (pl *ClusterHealth) Filter(_ context.Context, _ *framework.CycleState, deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) *framework.Status {
+	if clusterutil.IsReady(cluster) {
+		return nil
+	}
+
+	for _, decision := range deployable.Status.PlacementDecisions {
+		if decision.Cluster == cluster.Name {
+			return nil
+		}
+	}
+
+	return framework.NewStatus(framework.Unschedulable, "cluster is not ready")
+}
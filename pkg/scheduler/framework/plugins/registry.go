@@ -0,0 +1,35 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/clusteraffinity"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/clustercapacity"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/clusterhealth"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/tainttoleration"
+)
+
+// NewInTreeRegistry builds the registry with all the in-tree plugins
+func NewInTreeRegistry() framework.Registry {
+	return framework.Registry{
+		clusteraffinity.Name: clusteraffinity.New,
+		clustercapacity.Name: clustercapacity.New,
+		clusterhealth.Name:   clusterhealth.New,
+		tainttoleration.Name: tainttoleration.New,
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tainttoleration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/names"
+)
+
+// Name is the name of the plugin used in the plugin registry and configurations
+const Name = names.TaintToleration
+
+// TaintToleration is a plugin that checks the taints of clusters against the tolerations of Deployable,
+// the clusters with the NoSchedule taints not tolerated are filtered out unless already decided, the ones
+// with the NoExecute taints are filtered out once the tolerations expire, and the clusters with fewer
+// PreferNoSchedule taints not tolerated are preferred
+type TaintToleration struct{}
+
+var _ framework.FilterPlugin = &TaintToleration{}
+var _ framework.ScorePlugin = &TaintToleration{}
+var _ framework.ScoreExtensions = &TaintToleration{}
+
+// New initializes a new plugin and returns it
+func New(_ runtime.RawExtension, _ framework.Handle) (framework.Plugin, error) {
+	return &TaintToleration{}, nil
+}
+
+// Name returns name of the plugin
+func (pl *TaintToleration) Name() string {
+	return Name
+}
+
+// Filter checks if the taints of cluster are tolerated by the Deployable
+func (pl *TaintToleration) Filter(_ context.Context, _ *framework.CycleState, deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) *framework.Status {
+	tolerations := deployable.Spec.Placement.Tolerations
+
+	now := time.Now()
+	if evictAt, evict := clusterutil.EvictionTime(cluster.Spec.Taints, tolerations, now); evict && !evictAt.After(now) {
+		taint, _ := clusterutil.FindUntoleratedTaint(cluster.Spec.Taints, tolerations, corev1.TaintEffectNoExecute)
+		if taint == nil {
+			return framework.NewStatus(framework.Unschedulable, "the tolerations of NoExecute taints expired")
+		}
+		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cluster has the untolerated taint %s", taint.ToString()))
+	}
+
+	taint, untolerated := clusterutil.FindUntoleratedTaint(cluster.Spec.Taints, tolerations, corev1.TaintEffectNoSchedule)
+	if !untolerated {
+		return nil
+	}
+	for _, decision := range deployable.Status.PlacementDecisions {
+		if decision.Cluster == cluster.Name {
+			return nil
+		}
+	}
+	return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("cluster has the untolerated taint %s", taint.ToString()))
+}
+
+// Score returns the number of PreferNoSchedule taints not tolerated, which is reversed by NormalizeScore
+func (pl *TaintToleration) Score(_ context.Context, _ *framework.CycleState, deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster) (int64, *framework.Status) {
+	var count int64
+	for i := range cluster.Spec.Taints {
+		taint := &cluster.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule && !clusterutil.TaintTolerated(taint, deployable.Spec.Placement.Tolerations) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ScoreExtensions of the Score plugin
+func (pl *TaintToleration) ScoreExtensions() framework.ScoreExtensions {
+	return pl
+}
+
+// NormalizeScore gives the clusters with the fewest untolerated taints the highest score
+func (pl *TaintToleration) NormalizeScore(_ context.Context, _ *framework.CycleState, _ *appsv1alpha1.Deployable, scores framework.ClusterScoreList) *framework.Status {
+	var maxCount int64
+	for _, score := range scores {
+		if score.Score > maxCount {
+			maxCount = score.Score
+		}
+	}
+
+	for i := range scores {
+		if maxCount == 0 {
+			scores[i].Score = framework.MaxClusterScore
+			continue
+		}
+		scores[i].Score = framework.MaxClusterScore - scores[i].Score*framework.MaxClusterScore/maxCount
+	}
+	return nil
+}
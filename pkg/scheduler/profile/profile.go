@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework/plugins/names"
+)
+
+// Profile configures the plugins used by the scheduler
+//
+//	plugins:
+//	  score:
+//	    enabled:
+//	      - name: MyScore
+//	        weight: 2
+//	    disabled:
+//	      - name: "*"
+//	pluginConfig:
+//	  - name: MyScore
+//	    args:
+//	      foo: bar
+type Profile struct {
+	// Plugins specifies the set of plugins that should be enabled or disabled
+	// +optional
+	Plugins Plugins `json:"plugins,omitempty"`
+
+	// PluginConfig is an optional set of custom plugin arguments
+	// +optional
+	PluginConfig []PluginConfig `json:"pluginConfig,omitempty"`
+}
+
+// Plugins include multiple extension points
+type Plugins struct {
+	// +optional
+	PreFilter PluginSet `json:"preFilter,omitempty"`
+	// +optional
+	Filter PluginSet `json:"filter,omitempty"`
+	// +optional
+	Score PluginSet `json:"score,omitempty"`
+	// +optional
+	Reserve PluginSet `json:"reserve,omitempty"`
+}
+
+// PluginSet specifies enabled and disabled plugins for an extension point
+type PluginSet struct {
+	// Enabled plugins are called after the default plugins, a default plugin with the same name is replaced
+	// +optional
+	Enabled []Plugin `json:"enabled,omitempty"`
+	// Disabled plugins are removed from the default plugins, "*" disables all of them
+	// +optional
+	Disabled []Plugin `json:"disabled,omitempty"`
+}
+
+// Plugin specifies a plugin name and its weight when applicable
+type Plugin struct {
+	Name string `json:"name"`
+	// Weight is only used by Score plugins, defaults to 1
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+}
+
+// PluginConfig specifies arguments that should be passed to a plugin at the time of initialization
+type PluginConfig struct {
+	Name string `json:"name"`
+	// +optional
+	Args runtime.RawExtension `json:"args,omitempty"`
+}
+
+// Default returns the profile with the default in-tree plugins
+func Default() *Profile {
+	return &Profile{
+		Plugins: Plugins{
+			PreFilter: PluginSet{
+				Enabled: []Plugin{
+					{Name: names.ClusterAffinity},
+					{Name: names.ClusterCapacity},
+				},
+			},
+			Filter: PluginSet{
+				Enabled: []Plugin{
+					{Name: names.ClusterAffinity},
+					{Name: names.ClusterHealth},
+					{Name: names.TaintToleration},
+					{Name: names.ClusterCapacity},
+				},
+			},
+			Score: PluginSet{
+				Enabled: []Plugin{
+					{Name: names.TaintToleration},
+					{Name: names.ClusterCapacity},
+				},
+			},
+		},
+	}
+}
+
+// Load reads the profile from file and merges it into the default profile, the default is returned if file is empty
+func Load(file string) (*Profile, error) {
+	if file == "" {
+		return Default(), nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read scheduler profile %s: %v", file, err)
+	}
+
+	custom := &Profile{}
+	if err := yaml.UnmarshalStrict(data, custom); err != nil {
+		return nil, fmt.Errorf("unable to decode scheduler profile %s: %v", file, err)
+	}
+
+	return merge(Default(), custom), nil
+}
+
+func merge(defaults, custom *Profile) *Profile {
+	return &Profile{
+		Plugins: Plugins{
+			PreFilter: mergePluginSet(defaults.Plugins.PreFilter, custom.Plugins.PreFilter),
+			Filter:    mergePluginSet(defaults.Plugins.Filter, custom.Plugins.Filter),
+			Score:     mergePluginSet(defaults.Plugins.Score, custom.Plugins.Score),
+			Reserve:   mergePluginSet(defaults.Plugins.Reserve, custom.Plugins.Reserve),
+		},
+		PluginConfig: append(defaults.PluginConfig, custom.PluginConfig...),
+	}
+}
+
+func mergePluginSet(defaults, custom PluginSet) PluginSet {
+	disabled := sets.NewString()
+	for _, plugin := range custom.Disabled {
+		disabled.Insert(plugin.Name)
+	}
+
+	replaced := make(map[string]Plugin, len(custom.Enabled))
+	for _, plugin := range custom.Enabled {
+		replaced[plugin.Name] = plugin
+	}
+
+	var enabled []Plugin
+	if !disabled.Has("*") {
+		for _, plugin := range defaults.Enabled {
+			if disabled.Has(plugin.Name) {
+				continue
+			}
+			if customPlugin, ok := replaced[plugin.Name]; ok {
+				plugin = customPlugin
+				delete(replaced, plugin.Name)
+			}
+			enabled = append(enabled, plugin)
+		}
+	}
+
+	for _, plugin := range custom.Enabled {
+		if _, ok := replaced[plugin.Name]; ok {
+			enabled = append(enabled, plugin)
+		}
+	}
+
+	return PluginSet{Enabled: enabled}
+}
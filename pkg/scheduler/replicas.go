@@ -0,0 +1,268 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/workload"
+)
+
+// divideReplicas divides the replicas of each workload resource across the clusters by the strategy,
+// the result is keyed by cluster name and nil means the replicas are not divided. The replicas decided before
+// are kept unless the spec, the replicas of workloads or the clusters change, so they do not move between
+// clusters as the available resources change
+func (s *Scheduler) divideReplicas(ctx context.Context, deployable *appsv1alpha1.Deployable, clusterNames []string) (map[string][]appsv1alpha1.ResourceReplicas, error) {
+	replicaScheduling := deployable.Spec.Placement.ReplicaScheduling
+	if replicaScheduling == nil || len(clusterNames) == 0 {
+		return nil, nil
+	}
+
+	replicasChanged, err := s.replicasChanged(ctx, deployable)
+	if err != nil {
+		return nil, err
+	}
+	if deployable.Status.PlacementDecided && deployable.Status.ScheduledGeneration == deployable.Generation &&
+		!replicasChanged && sameClusters(deployable, clusterNames) {
+		result := make(map[string][]appsv1alpha1.ResourceReplicas, len(deployable.Status.PlacementDecisions))
+		for _, decision := range deployable.Status.PlacementDecisions {
+			result[decision.Cluster] = decision.Replicas
+		}
+		return result, nil
+	}
+
+	workloads, err := workload.List(ctx, s.Client, deployable)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make([]*clusterv1alpha1.Cluster, len(clusterNames))
+	for i, clusterName := range clusterNames {
+		clusters[i] = &clusterv1alpha1.Cluster{}
+		if err := s.Client.Get(ctx, client.ObjectKey{Name: clusterName}, clusters[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[string][]appsv1alpha1.ResourceReplicas, len(clusterNames))
+	for _, w := range workloads {
+		var replicas []int32
+		switch replicaScheduling.Strategy {
+		case appsv1alpha1.ReplicaDivisionStrategyStaticWeighted:
+			weights := make([]int64, len(clusters))
+			for i, cluster := range clusters {
+				for _, staticWeight := range replicaScheduling.StaticWeights {
+					if staticWeight.Cluster == cluster.Name {
+						weights[i] = staticWeight.Weight
+					}
+				}
+			}
+			replicas = divideByWeights(w.Replicas, weights)
+		case appsv1alpha1.ReplicaDivisionStrategyDynamicWeighted:
+			weights := make([]int64, len(clusters))
+			for i, cluster := range clusters {
+				weights[i] = availableReplicas(deployable, cluster, w)
+			}
+			replicas = divideByWeights(w.Replicas, weights)
+		case appsv1alpha1.ReplicaDivisionStrategyAggregated:
+			available := make([]int64, len(clusters))
+			for i, cluster := range clusters {
+				available[i] = availableReplicas(deployable, cluster, w)
+			}
+			replicas = aggregate(w.Replicas, available)
+		default:
+			return nil, fmt.Errorf("unknown replica division strategy %q", replicaScheduling.Strategy)
+		}
+
+		for i, cluster := range clusters {
+			result[cluster.Name] = append(result[cluster.Name], appsv1alpha1.ResourceReplicas{
+				Resource: w.Resource,
+				Replicas: replicas[i],
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// availableReplicas returns the replicas of workload fitting in the cluster, the replicas decided to the cluster
+// before are added back as their pods are counted in the used resources of cluster
+func availableReplicas(deployable *appsv1alpha1.Deployable, cluster *clusterv1alpha1.Cluster, w workload.Workload) int64 {
+	available := clusterutil.AvailableReplicas(cluster, w.PodRequests)
+	for _, decision := range deployable.Status.PlacementDecisions {
+		if decision.Cluster != cluster.Name {
+			continue
+		}
+		for _, replicas := range decision.Replicas {
+			if replicas.Resource == w.Resource {
+				available += int64(replicas.Replicas)
+			}
+		}
+	}
+	return available
+}
+
+// sameClusters returns true if the clusters decided before are the scheduled ones, the Aggregated strategy
+// drops the clusters without replicas, so they are only required to be scheduled still
+func sameClusters(deployable *appsv1alpha1.Deployable, clusterNames []string) bool {
+	scheduled := sets.NewString(clusterNames...)
+	decided := sets.NewString()
+	for _, decision := range deployable.Status.PlacementDecisions {
+		decided.Insert(decision.Cluster)
+	}
+	if deployable.Spec.Placement.ReplicaScheduling.Strategy == appsv1alpha1.ReplicaDivisionStrategyAggregated {
+		return decided.Len() > 0 && scheduled.IsSuperset(decided)
+	}
+	return scheduled.Equal(decided)
+}
+
+// packClusters drops the clusters without any replicas for the Aggregated strategy, so the resources of
+// Deployable are placed to as few clusters as possible. The first cluster is kept if all the replicas are zero
+func packClusters(deployable *appsv1alpha1.Deployable, clusterNames []string, replicas map[string][]appsv1alpha1.ResourceReplicas) []string {
+	replicaScheduling := deployable.Spec.Placement.ReplicaScheduling
+	if replicaScheduling == nil || replicaScheduling.Strategy != appsv1alpha1.ReplicaDivisionStrategyAggregated {
+		return clusterNames
+	}
+
+	packed := make([]string, 0, len(clusterNames))
+	var divided bool
+	for _, clusterName := range clusterNames {
+		divided = divided || len(replicas[clusterName]) > 0
+		for _, r := range replicas[clusterName] {
+			if r.Replicas > 0 {
+				packed = append(packed, clusterName)
+				break
+			}
+		}
+	}
+	// the Deployable without workloads is not packed
+	if !divided {
+		return clusterNames
+	}
+	if len(packed) == 0 {
+		packed = append(packed, clusterNames[0])
+	}
+	return packed
+}
+
+// replicasChanged returns true if the replicas of any workload resource differ from the ones divided in the
+// placement decisions, e.g. spec.replicas is edited in its Manifest
+func (s *Scheduler) replicasChanged(ctx context.Context, deployable *appsv1alpha1.Deployable) (bool, error) {
+	if deployable.Spec.Placement.ReplicaScheduling == nil || len(deployable.Status.PlacementDecisions) == 0 {
+		return false, nil
+	}
+
+	workloads, err := workload.List(ctx, s.Client, deployable)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, w := range workloads {
+		var divided int32
+		for _, decision := range deployable.Status.PlacementDecisions {
+			for _, replicas := range decision.Replicas {
+				if replicas.Resource == w.Resource {
+					divided += replicas.Replicas
+				}
+			}
+		}
+		if divided != w.Replicas {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// divideByWeights divides the replicas in proportion to weights with the largest remainder method,
+// the ties are broken by the order of clusters, and the replicas are divided equally if all weights are zero
+func divideByWeights(replicas int32, weights []int64) []int32 {
+	result := make([]int32, len(weights))
+
+	var sum int64
+	for _, weight := range weights {
+		sum += weight
+	}
+	if sum == 0 {
+		weights = make([]int64, len(weights))
+		for i := range weights {
+			weights[i] = 1
+		}
+		sum = int64(len(weights))
+	}
+
+	remainders := make([]int, len(weights))
+	assigned := int32(0)
+	for i, weight := range weights {
+		result[i] = int32(int64(replicas) * weight / sum)
+		assigned += result[i]
+		remainders[i] = i
+	}
+
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return int64(replicas)*weights[remainders[i]]%sum > int64(replicas)*weights[remainders[j]]%sum
+	})
+	for i := 0; assigned < replicas; i++ {
+		result[remainders[i%len(remainders)]]++
+		assigned++
+	}
+
+	return result
+}
+
+// aggregate packs the replicas into the clusters with the most available replicas first,
+// the replicas exceeding the available ones go to the first cluster
+func aggregate(replicas int32, available []int64) []int32 {
+	result := make([]int32, len(available))
+
+	order := make([]int, len(available))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return available[order[i]] > available[order[j]]
+	})
+
+	remaining := int64(replicas)
+	for _, i := range order {
+		if remaining == 0 {
+			break
+		}
+		assigned := available[i]
+		if assigned > remaining {
+			assigned = remaining
+		}
+		result[i] = int32(assigned)
+		remaining -= assigned
+	}
+	if remaining > 0 {
+		result[order[0]] += int32(remaining)
+	}
+
+	return result
+}
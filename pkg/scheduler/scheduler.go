@@ -18,30 +18,143 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"time"
 
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+	"github.com/multi-cluster-platform/mcp/pkg/scheduler/framework"
+)
+
+const (
+	// unschedulableRetryPeriod is the period to retry scheduling the unschedulable Deployables
+	unschedulableRetryPeriod = time.Minute
+
+	// manifestIndexKey is the index of Deployables by the Manifests they refer to
+	manifestIndexKey = "spec.resources.manifest"
 )
 
 type Scheduler struct {
 	client.Client
+
+	Framework framework.Framework
 }
 
 var _ reconcile.Reconciler = &Scheduler{}
 
 // SetupWithManager sets up the controller with the Manager.
 func (s *Scheduler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &appsv1alpha1.Deployable{}, manifestIndexKey, indexManifests); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appsv1alpha1.Deployable{}).
+		Watches(
+			&source.Kind{Type: &clusterv1alpha1.Cluster{}},
+			handler.EnqueueRequestsFromMapFunc(s.clusterToDeployables),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, clusterReadyChanged)),
+		).
+		Watches(
+			&source.Kind{Type: &appsv1alpha1.Manifest{}},
+			handler.EnqueueRequestsFromMapFunc(s.manifestToDeployables),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
 		Complete(s)
 }
 
+// clusterReadyChanged triggers the rescheduling when the cluster becomes ready or not ready
+var clusterReadyChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldCluster, ok := e.ObjectOld.(*clusterv1alpha1.Cluster)
+		if !ok {
+			return false
+		}
+		newCluster, ok := e.ObjectNew.(*clusterv1alpha1.Cluster)
+		if !ok {
+			return false
+		}
+		return clusterutil.IsReady(oldCluster) != clusterutil.IsReady(newCluster)
+	},
+}
+
+// clusterToDeployables maps the Cluster to the Deployables selecting clusters by labels or claims, listing it,
+// or placed to it, e.g. to evict the Deployables by its NoExecute taints
+func (s *Scheduler) clusterToDeployables(obj client.Object) []reconcile.Request {
+	deployables := &appsv1alpha1.DeployableList{}
+	if err := s.Client.List(context.TODO(), deployables); err != nil {
+		klog.ErrorS(err, "unable to list Deployables", "cluster", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range deployables.Items {
+		deployable := &deployables.Items[i]
+		if hasClusterSelector(deployable) || listsCluster(deployable, obj.GetName()) || decidesCluster(deployable, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(deployable),
+			})
+		}
+	}
+	return requests
+}
+
+// manifestToDeployables maps the Manifest to the Deployables dividing the replicas of its resource
+func (s *Scheduler) manifestToDeployables(obj client.Object) []reconcile.Request {
+	manifest, ok := obj.(*appsv1alpha1.Manifest)
+	if !ok {
+		return nil
+	}
+
+	name := manifest.Name
+	if resource, ok := appsv1alpha1.ManifestResource(manifest); ok {
+		name = appsv1alpha1.ManifestName(resource)
+	}
+
+	deployables := &appsv1alpha1.DeployableList{}
+	if err := s.Client.List(context.TODO(), deployables, client.MatchingFields{manifestIndexKey: manifest.Namespace + "/" + name}); err != nil {
+		klog.ErrorS(err, "unable to list Deployables for Manifest", "namespace", manifest.Namespace, "name", manifest.Name)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range deployables.Items {
+		if deployables.Items[i].Spec.Placement.ReplicaScheduling == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&deployables.Items[i])})
+	}
+	return requests
+}
+
+func indexManifests(obj client.Object) []string {
+	deployable, ok := obj.(*appsv1alpha1.Deployable)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, len(deployable.Spec.Resources))
+	for i, resource := range deployable.Spec.Resources {
+		keys[i] = appsv1alpha1.ManifestNamespace(resource) + "/" + appsv1alpha1.ManifestName(resource)
+	}
+	return keys
+}
+
 // Reconcile handles scheduleOne logic
 func (s *Scheduler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
 	klog.V(1).InfoS("reconcile for scheduler", "namespace", req.Namespace, "name", req.Name)
@@ -59,38 +172,196 @@ func (s *Scheduler) Reconcile(ctx context.Context, req reconcile.Request) (recon
 		return reconcile.Result{}, nil
 	}
 
-	if deployable.Status.PlacementDecided {
-		klog.V(1).InfoS("deployable is scheduled, skip", "namespace", deployable.Namespace, "name", deployable.Name)
-		return reconcile.Result{}, nil
+	// the clusters selected by labels or claims may change, the listed clusters may become ready, the replicas
+	// of workloads may be edited in their Manifests, and the unschedulable Deployable may become schedulable,
+	// so the Deployable is rescheduled in these cases
+	replicasChanged, err := s.replicasChanged(ctx, deployable)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if deployable.Status.PlacementDecided && deployable.Status.ScheduledGeneration == deployable.Generation &&
+		len(deployable.Status.UnschedulableReasons) == 0 && !hasClusterSelector(deployable) && !hasUndecidedCluster(deployable) && !replicasChanged {
+		evictNow, requeueAfter, err := s.checkEviction(ctx, deployable)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !evictNow {
+			klog.V(1).InfoS("deployable is scheduled, skip", "namespace", deployable.Namespace, "name", deployable.Name)
+			return reconcile.Result{RequeueAfter: requeueAfter}, nil
+		}
+		klog.InfoS("deployable is evicted by NoExecute taints", "namespace", deployable.Namespace, "name", deployable.Name)
 	}
 
-	return s.scheduleOne(ctx, deployable)
+	result, err := s.scheduleOne(ctx, deployable)
+	if err != nil {
+		return result, err
+	}
+
+	// the Deployable is rescheduled when the tolerations of NoExecute taints on the decided clusters expire
+	_, requeueAfter, err := s.checkEviction(ctx, deployable)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if requeueAfter > 0 && (result.RequeueAfter == 0 || requeueAfter < result.RequeueAfter) {
+		result.RequeueAfter = requeueAfter
+	}
+	return result, nil
 }
 
-func (s *Scheduler) scheduleOne(ctx context.Context, deployable *appsv1alpha1.Deployable) (reconcile.Result, error) {
-	deployable.Status.PlacementDecided = true
-	deployable.Status.Applied = false
+// checkEviction returns true if the Deployable should be evicted from any decided cluster by the NoExecute taints now,
+// or the duration after which the next eviction happens, zero means no eviction is expected
+func (s *Scheduler) checkEviction(ctx context.Context, deployable *appsv1alpha1.Deployable) (bool, time.Duration, error) {
+	now := time.Now()
 
-	resNum := len(deployable.Spec.Resources)
-	clusterNum := len(deployable.Spec.Placement.ClusterNames)
-	if clusterNum == 0 || resNum == 0 {
+	var requeueAfter time.Duration
+	for _, decision := range deployable.Status.PlacementDecisions {
+		cluster := &clusterv1alpha1.Cluster{}
+		if err := s.Client.Get(ctx, client.ObjectKey{Name: decision.Cluster}, cluster); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, 0, err
+		}
+
+		evictAt, evict := clusterutil.EvictionTime(cluster.Spec.Taints, deployable.Spec.Placement.Tolerations, now)
+		if !evict {
+			continue
+		}
+		if !evictAt.After(now) {
+			return true, 0, nil
+		}
+		if after := evictAt.Sub(now); requeueAfter == 0 || after < requeueAfter {
+			requeueAfter = after
+		}
+	}
+	return false, requeueAfter, nil
+}
+
+// retainDecisions returns the placement decisions of Deployable except the clusters evicting it by NoExecute taints now
+func (s *Scheduler) retainDecisions(ctx context.Context, deployable *appsv1alpha1.Deployable) ([]appsv1alpha1.PlacementDecision, error) {
+	now := time.Now()
+
+	decisions := make([]appsv1alpha1.PlacementDecision, 0, len(deployable.Status.PlacementDecisions))
+	for _, decision := range deployable.Status.PlacementDecisions {
+		cluster := &clusterv1alpha1.Cluster{}
+		if err := s.Client.Get(ctx, client.ObjectKey{Name: decision.Cluster}, cluster); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if evictAt, evict := clusterutil.EvictionTime(cluster.Spec.Taints, deployable.Spec.Placement.Tolerations, now); evict && !evictAt.After(now) {
+			continue
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, nil
+}
+
+func (s *Scheduler) scheduleOne(ctx context.Context, deployable *appsv1alpha1.Deployable) (reconcile.Result, error) {
+	if len(deployable.Spec.Resources) == 0 && !deployable.Status.PlacementDecided {
 		return reconcile.Result{}, nil
 	}
 
-	deployable.Status.PlacementDecisions = make([]appsv1alpha1.PlacementDecision, clusterNum)
-	for i := 0; i < clusterNum; i++ {
-		deployable.Status.PlacementDecisions[i] = appsv1alpha1.PlacementDecision{
-			Cluster:   deployable.Spec.Placement.ClusterNames[i],
+	var clusters []string
+	var unschedulableReasons map[string]string
+	// the Deployable without resources is placed nowhere, so the ManifestWorks of previous decisions are deleted
+	if len(deployable.Spec.Resources) > 0 {
+		var err error
+		clusters, unschedulableReasons, err = s.schedule(ctx, deployable)
+		if err != nil {
+			klog.ErrorS(err, "unable to schedule Deployable", "namespace", deployable.Namespace, "name", deployable.Name)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// the resources of clusters change without notifications, so the unschedulable Deployable is retried periodically
+	result := reconcile.Result{}
+	if len(unschedulableReasons) > 0 {
+		result.RequeueAfter = unschedulableRetryPeriod
+	}
+
+	// the placed Deployable turning unschedulable keeps running in the decided clusters, except the ones evicting it
+	// by NoExecute taints, and it is rescheduled until schedulable again
+	if len(unschedulableReasons) > 0 && deployable.Status.PlacementDecided {
+		decisions, err := s.retainDecisions(ctx, deployable)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		klog.InfoS("deployable is unschedulable, keep the placement decisions", "namespace", deployable.Namespace, "name", deployable.Name, "clusters", len(decisions))
+		if apiequality.Semantic.DeepEqual(deployable.Status.PlacementDecisions, decisions) &&
+			apiequality.Semantic.DeepEqual(deployable.Status.UnschedulableReasons, unschedulableReasons) {
+			return result, nil
+		}
+
+		deployable.Status.PlacementDecisions = decisions
+		deployable.Status.UnschedulableReasons = unschedulableReasons
+		runtimeObject := deployable.DeepCopy()
+		if _, err := controllerutil.CreateOrPatch(ctx, s.Client, runtimeObject, func() error {
+			runtimeObject.Status.PlacementDecisions = deployable.Status.PlacementDecisions
+			runtimeObject.Status.UnschedulableReasons = deployable.Status.UnschedulableReasons
+			return nil
+		}); err != nil {
+			klog.ErrorS(err, "unable to patch unschedulable reasons for Deployable", "namespace", deployable.Namespace, "name", deployable.Name)
+			return reconcile.Result{}, err
+		}
+		return result, nil
+	}
+
+	if len(clusters) == 0 && !deployable.Status.PlacementDecided {
+		klog.InfoS("no cluster is available for Deployable", "namespace", deployable.Namespace, "name", deployable.Name)
+		if apiequality.Semantic.DeepEqual(deployable.Status.UnschedulableReasons, unschedulableReasons) {
+			return result, nil
+		}
+
+		deployable.Status.UnschedulableReasons = unschedulableReasons
+		runtimeObject := deployable.DeepCopy()
+		if _, err := controllerutil.CreateOrPatch(ctx, s.Client, runtimeObject, func() error {
+			runtimeObject.Status.UnschedulableReasons = deployable.Status.UnschedulableReasons
+			return nil
+		}); err != nil {
+			klog.ErrorS(err, "unable to patch unschedulable reasons for Deployable", "namespace", deployable.Namespace, "name", deployable.Name)
+			return reconcile.Result{}, err
+		}
+		return result, nil
+	}
+
+	replicas, err := s.divideReplicas(ctx, deployable, clusters)
+	if err != nil {
+		klog.ErrorS(err, "unable to divide replicas for Deployable", "namespace", deployable.Namespace, "name", deployable.Name)
+		return reconcile.Result{}, err
+	}
+	clusters = packClusters(deployable, clusters, replicas)
+
+	decisions := make([]appsv1alpha1.PlacementDecision, len(clusters))
+	for i, cluster := range clusters {
+		decisions[i] = appsv1alpha1.PlacementDecision{
+			Cluster:   cluster,
 			Resources: deployable.Spec.Resources,
+			Replicas:  replicas[cluster],
 		}
 	}
 
+	if deployable.Status.PlacementDecided && deployable.Status.ScheduledGeneration == deployable.Generation &&
+		apiequality.Semantic.DeepEqual(deployable.Status.PlacementDecisions, decisions) &&
+		apiequality.Semantic.DeepEqual(deployable.Status.UnschedulableReasons, unschedulableReasons) {
+		klog.V(1).InfoS("placement decisions are not changed", "namespace", deployable.Namespace, "name", deployable.Name)
+		return result, nil
+	}
+
+	deployable.Status.PlacementDecided = true
+	deployable.Status.PlacementDecisions = decisions
+	deployable.Status.ScheduledGeneration = deployable.Generation
+	deployable.Status.UnschedulableReasons = unschedulableReasons
+
 	// bind
 	runtimeObject := deployable.DeepCopy()
-	_, err := controllerutil.CreateOrPatch(ctx, s.Client, runtimeObject, func() error {
-		runtimeObject.Status.Applied = deployable.Status.Applied
+	_, err = controllerutil.CreateOrPatch(ctx, s.Client, runtimeObject, func() error {
 		runtimeObject.Status.PlacementDecided = deployable.Status.PlacementDecided
 		runtimeObject.Status.PlacementDecisions = deployable.Status.PlacementDecisions
+		runtimeObject.Status.ScheduledGeneration = deployable.Status.ScheduledGeneration
+		runtimeObject.Status.UnschedulableReasons = deployable.Status.UnschedulableReasons
 		return nil
 	})
 	if err != nil {
@@ -98,5 +369,125 @@ func (s *Scheduler) scheduleOne(ctx context.Context, deployable *appsv1alpha1.De
 		return reconcile.Result{}, err
 	}
 
-	return reconcile.Result{}, nil
+	return result, nil
+}
+
+// schedule runs the plugins against the registered clusters and returns the selected ones ordered by score,
+// an empty result means the Deployable is unschedulable, with the reasons keyed by the filtered clusters
+func (s *Scheduler) schedule(ctx context.Context, deployable *appsv1alpha1.Deployable) ([]string, map[string]string, error) {
+	clusterList := &clusterv1alpha1.ClusterList{}
+	if err := s.Client.List(ctx, clusterList); err != nil {
+		return nil, nil, err
+	}
+
+	state := framework.NewCycleState()
+	if status := s.Framework.RunPreFilterPlugins(ctx, state, deployable); !status.IsSuccess() {
+		if status.IsUnschedulable() {
+			klog.V(1).InfoS("deployable is rejected", "namespace", deployable.Namespace, "name", deployable.Name, "plugin", status.Plugin(), "reason", status.Message())
+			return nil, map[string]string{"": formatReason(status)}, nil
+		}
+		return nil, nil, status.AsError()
+	}
+
+	feasibleClusters := make([]*clusterv1alpha1.Cluster, 0, len(clusterList.Items))
+	reasons := map[string]string{}
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		status := s.Framework.RunFilterPlugins(ctx, state, deployable, cluster)
+		if status.IsSuccess() {
+			feasibleClusters = append(feasibleClusters, cluster)
+			continue
+		}
+		if !status.IsUnschedulable() {
+			return nil, nil, status.AsError()
+		}
+		klog.V(2).InfoS("cluster is filtered", "namespace", deployable.Namespace, "name", deployable.Name, "cluster", cluster.Name, "plugin", status.Plugin(), "reason", status.Message())
+		reasons[cluster.Name] = formatReason(status)
+	}
+	if len(feasibleClusters) == 0 {
+		if len(reasons) == 0 {
+			reasons[""] = "no cluster is registered"
+		}
+		return nil, reasons, nil
+	}
+
+	scores, status := s.Framework.RunScorePlugins(ctx, state, deployable, feasibleClusters)
+	if !status.IsSuccess() {
+		return nil, nil, status.AsError()
+	}
+	// the name breaks the tie, so repeated scheduling is stable
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Name < scores[j].Name
+	})
+
+	feasible := make(map[string]*clusterv1alpha1.Cluster, len(feasibleClusters))
+	for _, cluster := range feasibleClusters {
+		feasible[cluster.Name] = cluster
+	}
+	rankedClusters := make([]*clusterv1alpha1.Cluster, len(scores))
+	for i, score := range scores {
+		rankedClusters[i] = feasible[score.Name]
+	}
+
+	clusters, reason := spreadClusters(deployable, rankedClusters)
+	if reason != "" {
+		klog.V(1).InfoS("deployable is rejected", "namespace", deployable.Namespace, "name", deployable.Name, "reason", reason)
+		return nil, map[string]string{"": reason}, nil
+	}
+
+	if status := s.Framework.RunReservePlugins(ctx, state, deployable, clusters); !status.IsSuccess() {
+		if status.IsUnschedulable() {
+			klog.V(1).InfoS("deployable is rejected", "namespace", deployable.Namespace, "name", deployable.Name, "plugin", status.Plugin(), "reason", status.Message())
+			return nil, map[string]string{"": formatReason(status)}, nil
+		}
+		return nil, nil, status.AsError()
+	}
+
+	return clusters, nil, nil
+}
+
+// formatReason returns the message of Status prefixed by the plugin
+func formatReason(status *framework.Status) string {
+	return fmt.Sprintf("%s: %s", status.Plugin(), status.Message())
+}
+
+func hasClusterSelector(deployable *appsv1alpha1.Deployable) bool {
+	return deployable.Spec.Placement.ClusterSelector != nil || deployable.Spec.Placement.ClaimSelector != nil
+}
+
+// listsCluster returns true if the cluster is listed in the clusterNames of Deployable
+func listsCluster(deployable *appsv1alpha1.Deployable, cluster string) bool {
+	for _, clusterName := range deployable.Spec.Placement.ClusterNames {
+		if clusterName == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+// hasUndecidedCluster returns true if any listed cluster is not in the decisions, e.g. filtered out as not ready
+func hasUndecidedCluster(deployable *appsv1alpha1.Deployable) bool {
+	decided := make(map[string]bool, len(deployable.Status.PlacementDecisions))
+	for _, decision := range deployable.Status.PlacementDecisions {
+		decided[decision.Cluster] = true
+	}
+	for _, clusterName := range deployable.Spec.Placement.ClusterNames {
+		if !decided[clusterName] {
+			return true
+		}
+	}
+	return false
+}
+
+// decidesCluster returns true if the Deployable is placed to the cluster
+func decidesCluster(deployable *appsv1alpha1.Deployable, cluster string) bool {
+	for _, decision := range deployable.Status.PlacementDecisions {
+		if decision.Cluster == cluster {
+			return true
+		}
+	}
+	return false
 }
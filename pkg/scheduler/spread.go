@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+)
+
+// spreadClusters selects the clusters satisfying the spread constraints from the ones ordered by rank, the
+// selected ones keep the order. The groups are filled in turns, each turn adds at most one cluster to every
+// group, and the decided clusters go first in their groups so the repeated scheduling is stable. A reason is
+// returned if the minGroups of any constraint is not satisfied
+func spreadClusters(deployable *appsv1alpha1.Deployable, clusters []*clusterv1alpha1.Cluster) ([]string, string) {
+	constraints := deployable.Spec.Placement.SpreadConstraints
+	if len(constraints) == 0 {
+		names := make([]string, len(clusters))
+		for i, cluster := range clusters {
+			names[i] = cluster.Name
+		}
+		return names, ""
+	}
+
+	decided := sets.NewString()
+	if deployable.Status.PlacementDecided {
+		for _, decision := range deployable.Status.PlacementDecisions {
+			decided.Insert(decision.Cluster)
+		}
+	}
+
+	// the groups of each candidate, indexed by the constraints
+	type candidate struct {
+		name   string
+		groups []string
+	}
+	candidates := make([]candidate, 0, len(clusters))
+	for _, cluster := range clusters {
+		groups := make([]string, len(constraints))
+		grouped := true
+		for i := range constraints {
+			if groups[i], grouped = spreadGroup(&constraints[i], cluster); !grouped {
+				break
+			}
+		}
+		if grouped {
+			candidates = append(candidates, candidate{name: cluster.Name, groups: groups})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return decided.Has(candidates[i].name) && !decided.Has(candidates[j].name)
+	})
+
+	// the number of selected clusters in each group, indexed by the constraints
+	counts := make([]map[string]int32, len(constraints))
+	for i := range counts {
+		counts[i] = map[string]int32{}
+	}
+	selected := sets.NewString()
+	for turn := int32(1); ; turn++ {
+		added := false
+		for _, c := range candidates {
+			if selected.Has(c.name) || !spreadAllowed(constraints, counts, c.groups, turn) {
+				continue
+			}
+			for i, group := range c.groups {
+				counts[i][group]++
+			}
+			selected.Insert(c.name)
+			added = true
+		}
+		if !added {
+			break
+		}
+	}
+
+	for i := range constraints {
+		minGroups := constraints[i].MinGroups
+		if minGroups < 1 {
+			minGroups = 1
+		}
+		if groups := int32(len(counts[i])); groups < minGroups {
+			return nil, fmt.Sprintf("spread by %s: %d groups are selected, at least %d are required", spreadKey(&constraints[i]), groups, minGroups)
+		}
+	}
+
+	names := make([]string, 0, selected.Len())
+	for _, cluster := range clusters {
+		if selected.Has(cluster.Name) {
+			names = append(names, cluster.Name)
+		}
+	}
+	return names, ""
+}
+
+// spreadAllowed returns true if the cluster of the groups could be added in the turn without breaking any constraint
+func spreadAllowed(constraints []appsv1alpha1.SpreadConstraint, counts []map[string]int32, groups []string, turn int32) bool {
+	for i := range constraints {
+		constraint := &constraints[i]
+		count := counts[i][groups[i]]
+		if count >= turn {
+			return false
+		}
+		if count == 0 && constraint.MaxGroups > 0 && int32(len(counts[i])) >= constraint.MaxGroups {
+			return false
+		}
+		if constraint.MaxClustersPerGroup > 0 && count >= constraint.MaxClustersPerGroup {
+			return false
+		}
+		if constraint.MaxSkew > 0 && count+1-minCount(counts[i]) > constraint.MaxSkew {
+			return false
+		}
+	}
+	return true
+}
+
+func minCount(counts map[string]int32) int32 {
+	var min int32
+	for _, count := range counts {
+		if min == 0 || count < min {
+			min = count
+		}
+	}
+	return min
+}
+
+// spreadGroup returns the group of the cluster, false if the cluster has no value of the field or label
+func spreadGroup(constraint *appsv1alpha1.SpreadConstraint, cluster *clusterv1alpha1.Cluster) (string, bool) {
+	if constraint.SpreadByLabel != "" {
+		value, ok := cluster.Labels[constraint.SpreadByLabel]
+		return value, ok
+	}
+
+	var value string
+	switch constraint.SpreadByField {
+	case appsv1alpha1.SpreadFieldCluster:
+		value = cluster.Name
+	case appsv1alpha1.SpreadFieldRegion:
+		value = cluster.Spec.Region
+	case appsv1alpha1.SpreadFieldZone:
+		value = cluster.Spec.Zone
+	}
+	return value, value != ""
+}
+
+func spreadKey(constraint *appsv1alpha1.SpreadConstraint) string {
+	if constraint.SpreadByLabel != "" {
+		return fmt.Sprintf("label %s", constraint.SpreadByLabel)
+	}
+	return fmt.Sprintf("field %s", constraint.SpreadByField)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterutil "github.com/multi-cluster-platform/mcp/pkg/cluster"
+)
+
+// Workload is a resource whose replicas could be divided across clusters
+type Workload struct {
+	Resource    corev1.ObjectReference
+	Replicas    int32
+	PodRequests corev1.ResourceList
+}
+
+// List reads the Deployments and StatefulSets of the Deployable from their Manifests
+func List(ctx context.Context, reader client.Reader, deployable *appsv1alpha1.Deployable) ([]Workload, error) {
+	var workloads []Workload
+	for _, resource := range deployable.Spec.Resources {
+		gvk := schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind)
+		if gvk.Group != appsv1.GroupName || (gvk.Kind != "Deployment" && gvk.Kind != "StatefulSet") {
+			continue
+		}
+
+		manifest := &appsv1alpha1.Manifest{}
+		if err := reader.Get(ctx, client.ObjectKey{Namespace: appsv1alpha1.ManifestNamespace(resource), Name: appsv1alpha1.ManifestName(resource)}, manifest); err != nil {
+			return nil, err
+		}
+
+		// Deployment and StatefulSet share the same fields of replicas and pod template
+		template := &struct {
+			Spec struct {
+				Replicas *int32                 `json:"replicas,omitempty"`
+				Template corev1.PodTemplateSpec `json:"template"`
+			} `json:"spec"`
+		}{}
+		if err := json.Unmarshal(manifest.Template.Raw, template); err != nil {
+			return nil, fmt.Errorf("unable to decode template of Manifest %s/%s: %v", manifest.Namespace, manifest.Name, err)
+		}
+
+		replicas := int32(1)
+		if template.Spec.Replicas != nil {
+			replicas = *template.Spec.Replicas
+		}
+		workloads = append(workloads, Workload{
+			Resource:    resource,
+			Replicas:    replicas,
+			PodRequests: clusterutil.PodRequests(&template.Spec.Template.Spec),
+		})
+	}
+	return workloads, nil
+}
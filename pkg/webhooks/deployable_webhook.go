@@ -0,0 +1,365 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	clusterv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/cluster/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+// DeployableWebhook defaults the Deployable, and validates the referred Manifests and Clusters exist
+type DeployableWebhook struct {
+	// Reader reads the Manifests and Clusters from apiserver, they may be created along with the Deployable
+	client.Reader
+}
+
+var _ admission.CustomDefaulter = &DeployableWebhook{}
+var _ admission.CustomValidator = &DeployableWebhook{}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (w *DeployableWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&appsv1alpha1.Deployable{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default implements admission.CustomDefaulter
+func (w *DeployableWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	deployable, ok := obj.(*appsv1alpha1.Deployable)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Deployable but got a %T", obj))
+	}
+
+	// the resources are in the namespace of Deployable, except the cluster scope ones in mcp-system
+	if deployable.Namespace != constants.SystemNamespace {
+		for i := range deployable.Spec.Resources {
+			if deployable.Spec.Resources[i].Namespace == "" {
+				deployable.Spec.Resources[i].Namespace = deployable.Namespace
+			}
+		}
+	}
+
+	if scheduling := deployable.Spec.Placement.ReplicaScheduling; scheduling != nil && scheduling.Strategy == "" {
+		scheduling.Strategy = appsv1alpha1.ReplicaDivisionStrategyStaticWeighted
+	}
+	return nil
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (w *DeployableWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return w.validate(ctx, nil, obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (w *DeployableWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	old, ok := oldObj.(*appsv1alpha1.Deployable)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Deployable but got a %T", oldObj))
+	}
+	return w.validate(ctx, old, newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (w *DeployableWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validate checks the Deployable, the existence is only checked for the Manifests and Clusters not referred by
+// the old one, so the updates of metadata are not rejected after a Manifest or Cluster is removed
+func (w *DeployableWebhook) validate(ctx context.Context, old *appsv1alpha1.Deployable, obj runtime.Object) error {
+	deployable, ok := obj.(*appsv1alpha1.Deployable)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Deployable but got a %T", obj))
+	}
+
+	// the deleting Deployable only removes its finalizer
+	if !deployable.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	existingManifests, existingClusters := sets.NewString(), sets.NewString()
+	if old != nil {
+		for _, resource := range old.Spec.Resources {
+			existingManifests.Insert(manifestKey(resource).String())
+		}
+		existingClusters.Insert(old.Spec.Placement.ClusterNames...)
+	}
+
+	specPath := field.NewPath("spec")
+	errs, err := w.validateResources(ctx, deployable.Spec.Resources, existingManifests, specPath.Child("resources"))
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	placementErrs, err := w.validatePlacement(ctx, &deployable.Spec.Placement, existingClusters, specPath.Child("placement"))
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	errs = append(errs, placementErrs...)
+	errs = append(errs, validateOverrides(deployable.Spec.Overrides, specPath.Child("overrides"))...)
+	errs = append(errs, validateDependsOn(deployable, specPath.Child("dependsOn"))...)
+	if strategy := deployable.Spec.RolloutStrategy; strategy != nil {
+		strategyPath := specPath.Child("rolloutStrategy")
+		errs = append(errs, validateIntOrPercent(strategy.BatchSize, strategyPath.Child("batchSize"))...)
+		errs = append(errs, validateIntOrPercent(strategy.MaxUnavailable, strategyPath.Child("maxUnavailable"))...)
+	}
+
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(appsv1alpha1.Kind("Deployable"), deployable.Name, errs)
+	}
+	return nil
+}
+
+func (w *DeployableWebhook) validateResources(ctx context.Context, resources []corev1.ObjectReference, existing sets.String, path *field.Path) (field.ErrorList, error) {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, resource := range resources {
+		idxPath := path.Index(i)
+		if resource.APIVersion == "" {
+			errs = append(errs, field.Required(idxPath.Child("apiVersion"), ""))
+		}
+		if resource.Kind == "" {
+			errs = append(errs, field.Required(idxPath.Child("kind"), ""))
+		}
+		if resource.Name == "" {
+			errs = append(errs, field.Required(idxPath.Child("name"), ""))
+		}
+		if resource.APIVersion == "" || resource.Kind == "" || resource.Name == "" {
+			continue
+		}
+
+		key := manifestKey(resource)
+		if seen.Has(key.String()) {
+			errs = append(errs, field.Duplicate(idxPath, resource))
+			continue
+		}
+		seen.Insert(key.String())
+		if existing.Has(key.String()) {
+			continue
+		}
+
+		if err := w.Reader.Get(ctx, key, &appsv1alpha1.Manifest{}); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			errs = append(errs, field.NotFound(idxPath, fmt.Sprintf("Manifest %s", key)))
+		}
+	}
+	return errs, nil
+}
+
+func (w *DeployableWebhook) validatePlacement(ctx context.Context, placement *appsv1alpha1.Placement, existing sets.String, path *field.Path) (field.ErrorList, error) {
+	var errs field.ErrorList
+
+	clusterNamesPath := path.Child("clusterNames")
+	seen := sets.NewString()
+	for i, name := range placement.ClusterNames {
+		if seen.Has(name) {
+			errs = append(errs, field.Duplicate(clusterNamesPath.Index(i), name))
+			continue
+		}
+		seen.Insert(name)
+		if existing.Has(name) {
+			continue
+		}
+
+		if err := w.Reader.Get(ctx, client.ObjectKey{Name: name}, &clusterv1alpha1.Cluster{}); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			errs = append(errs, field.NotFound(clusterNamesPath.Index(i), name))
+		}
+	}
+
+	if placement.ClusterSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(placement.ClusterSelector); err != nil {
+			errs = append(errs, field.Invalid(path.Child("clusterSelector"), placement.ClusterSelector, err.Error()))
+		}
+	}
+	if placement.ClaimSelector != nil {
+		selector := &metav1.LabelSelector{MatchExpressions: placement.ClaimSelector.MatchExpressions}
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			errs = append(errs, field.Invalid(path.Child("claimSelector"), placement.ClaimSelector, err.Error()))
+		}
+	}
+
+	errs = append(errs, validateTolerations(placement.Tolerations, path.Child("tolerations"))...)
+	errs = append(errs, validateSpreadConstraints(placement.SpreadConstraints, path.Child("spreadConstraints"))...)
+
+	if scheduling := placement.ReplicaScheduling; scheduling != nil {
+		weightsPath := path.Child("replicaScheduling", "staticWeights")
+		seen := sets.NewString()
+		for i, weight := range scheduling.StaticWeights {
+			if seen.Has(weight.Cluster) {
+				errs = append(errs, field.Duplicate(weightsPath.Index(i).Child("cluster"), weight.Cluster))
+			}
+			seen.Insert(weight.Cluster)
+		}
+	}
+
+	return errs, nil
+}
+
+// validateTolerations checks the tolerations the same as the ones of Pods
+func validateTolerations(tolerations []corev1.Toleration, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, toleration := range tolerations {
+		idxPath := path.Index(i)
+
+		switch toleration.Operator {
+		case corev1.TolerationOpEqual, "":
+		case corev1.TolerationOpExists:
+			if toleration.Value != "" {
+				errs = append(errs, field.Invalid(idxPath.Child("value"), toleration.Value, "value must be empty when `operator` is 'Exists'"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(idxPath.Child("operator"), toleration.Operator, []string{string(corev1.TolerationOpEqual), string(corev1.TolerationOpExists)}))
+		}
+		if toleration.Key == "" && toleration.Operator != corev1.TolerationOpExists {
+			errs = append(errs, field.Invalid(idxPath.Child("operator"), toleration.Operator, "operator must be Exists when `key` is empty"))
+		}
+
+		switch toleration.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute, "":
+		default:
+			errs = append(errs, field.NotSupported(idxPath.Child("effect"), toleration.Effect,
+				[]string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}))
+		}
+		if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+			errs = append(errs, field.Invalid(idxPath.Child("effect"), toleration.Effect, "effect must be 'NoExecute' when `tolerationSeconds` is set"))
+		}
+	}
+	return errs
+}
+
+// validateSpreadConstraints checks each constraint groups the clusters by either a field or a label,
+// and the minGroups is reachable
+func validateSpreadConstraints(constraints []appsv1alpha1.SpreadConstraint, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, constraint := range constraints {
+		idxPath := path.Index(i)
+
+		switch {
+		case constraint.SpreadByField == "" && constraint.SpreadByLabel == "":
+			errs = append(errs, field.Required(idxPath, "either `spreadByField` or `spreadByLabel` is required"))
+		case constraint.SpreadByField != "" && constraint.SpreadByLabel != "":
+			errs = append(errs, field.Invalid(idxPath.Child("spreadByLabel"), constraint.SpreadByLabel, "must be empty when `spreadByField` is set"))
+		case constraint.SpreadByLabel != "":
+			for _, msg := range validation.IsQualifiedName(constraint.SpreadByLabel) {
+				errs = append(errs, field.Invalid(idxPath.Child("spreadByLabel"), constraint.SpreadByLabel, msg))
+			}
+		}
+
+		if constraint.MaxGroups > 0 && constraint.MinGroups > constraint.MaxGroups {
+			errs = append(errs, field.Invalid(idxPath.Child("minGroups"), constraint.MinGroups, "must not be greater than `maxGroups`"))
+		}
+	}
+	return errs
+}
+
+// validateDependsOn checks the Deployables depended on are named properly, the ones not created yet are allowed
+func validateDependsOn(deployable *appsv1alpha1.Deployable, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := sets.NewString()
+	for i, name := range deployable.Spec.DependsOn {
+		idxPath := path.Index(i)
+		if seen.Has(name) {
+			errs = append(errs, field.Duplicate(idxPath, name))
+			continue
+		}
+		seen.Insert(name)
+
+		if name == deployable.Name {
+			errs = append(errs, field.Invalid(idxPath, name, "a Deployable could not depend on itself"))
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			errs = append(errs, field.Invalid(idxPath, name, msg))
+		}
+	}
+	return errs
+}
+
+// validateIntOrPercent checks the value is a positive integer or percentage
+func validateIntOrPercent(value *intstr.IntOrString, path *field.Path) field.ErrorList {
+	if value == nil {
+		return nil
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, value.String(), err.Error())}
+	}
+	if scaled <= 0 {
+		return field.ErrorList{field.Invalid(path, value.String(), "must be greater than 0")}
+	}
+	return nil
+}
+
+func validateOverrides(overrides []appsv1alpha1.Override, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i := range overrides {
+		override := &overrides[i]
+		idxPath := path.Index(i)
+
+		if override.ClusterSelector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(override.ClusterSelector); err != nil {
+				errs = append(errs, field.Invalid(idxPath.Child("clusterSelector"), override.ClusterSelector, err.Error()))
+			}
+		}
+
+		overridersPath := idxPath.Child("overriders")
+		if patch := override.Overriders.StrategicMergePatch; patch != nil {
+			var obj map[string]interface{}
+			if err := json.Unmarshal(patch.Raw, &obj); err != nil {
+				errs = append(errs, field.Invalid(overridersPath.Child("strategicMergePatch"), string(patch.Raw), err.Error()))
+			}
+		}
+		if len(override.Overriders.JSONPatches) > 0 {
+			data, err := json.Marshal(override.Overriders.JSONPatches)
+			if err == nil {
+				_, err = jsonpatch.DecodePatch(data)
+			}
+			if err != nil {
+				errs = append(errs, field.Invalid(overridersPath.Child("jsonPatches"), override.Overriders.JSONPatches, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+func manifestKey(resource corev1.ObjectReference) client.ObjectKey {
+	return client.ObjectKey{Namespace: appsv1alpha1.ManifestNamespace(resource), Name: appsv1alpha1.ManifestName(resource)}
+}
@@ -0,0 +1,23 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:webhook:path=/mutate-apps-mcp-io-v1alpha1-deployable,mutating=true,failurePolicy=fail,sideEffects=None,groups=apps.mcp.io,resources=deployables,verbs=create;update,versions=v1alpha1,name=mdeployable.apps.mcp.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-apps-mcp-io-v1alpha1-deployable,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.mcp.io,resources=deployables,verbs=create;update,versions=v1alpha1,name=vdeployable.apps.mcp.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/mutate-apps-mcp-io-v1alpha1-manifest,mutating=true,failurePolicy=fail,sideEffects=None,groups=apps.mcp.io,resources=manifests,verbs=create;update,versions=v1alpha1,name=mmanifest.apps.mcp.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-apps-mcp-io-v1alpha1-manifest,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.mcp.io,resources=manifests,verbs=create;update,versions=v1alpha1,name=vmanifest.apps.mcp.io,admissionReviewVersions=v1
+
+// Package webhooks validates and defaults Deployables and Manifests at admission
+package webhooks
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/multi-cluster-platform/mcp/pkg/apis/apps/v1alpha1"
+	"github.com/multi-cluster-platform/mcp/pkg/constants"
+)
+
+// ManifestWebhook defaults the ManifestLabel* labels of Manifest from its template, and validates they match
+type ManifestWebhook struct{}
+
+var _ admission.CustomDefaulter = &ManifestWebhook{}
+var _ admission.CustomValidator = &ManifestWebhook{}
+
+// SetupWebhookWithManager sets up the webhook with the Manager.
+func (w *ManifestWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&appsv1alpha1.Manifest{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default implements admission.CustomDefaulter
+func (w *ManifestWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	manifest, ok := obj.(*appsv1alpha1.Manifest)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Manifest but got a %T", obj))
+	}
+
+	resource, err := templateResource(manifest)
+	if err != nil {
+		// rejected by validation
+		return nil
+	}
+	gvk := schema.FromAPIVersionAndKind(resource.APIVersion, resource.Kind)
+
+	if manifest.Labels == nil {
+		manifest.Labels = make(map[string]string, 5)
+	}
+	for key, value := range map[string]string{
+		constants.ManifestLabelAPIGroup:   gvk.Group,
+		constants.ManifestLabelAPIVersion: gvk.Version,
+		constants.ManifestLabelKind:       gvk.Kind,
+		constants.ManifestLabelNamespace:  resource.Namespace,
+		constants.ManifestLabelName:       resource.Name,
+	} {
+		if _, ok := manifest.Labels[key]; !ok {
+			manifest.Labels[key] = value
+		}
+	}
+	return nil
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (w *ManifestWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return w.validate(obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (w *ManifestWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return w.validate(newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (w *ManifestWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func (w *ManifestWebhook) validate(obj runtime.Object) error {
+	manifest, ok := obj.(*appsv1alpha1.Manifest)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a Manifest but got a %T", obj))
+	}
+
+	var errs field.ErrorList
+	templatePath := field.NewPath("template")
+	resource, err := templateResource(manifest)
+	if err != nil {
+		errs = append(errs, field.Invalid(templatePath, string(manifest.Template.Raw), err.Error()))
+		return apierrors.NewInvalid(appsv1alpha1.Kind("Manifest"), manifest.Name, errs)
+	}
+
+	if namespace := appsv1alpha1.ManifestNamespace(resource); namespace != manifest.Namespace {
+		errs = append(errs, field.Invalid(templatePath.Child("metadata", "namespace"), resource.Namespace,
+			fmt.Sprintf("the Manifest of resource must be in namespace %s", namespace)))
+	}
+	if name := appsv1alpha1.ManifestName(resource); name != manifest.Name {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), manifest.Name,
+			fmt.Sprintf("the Manifest of resource must be named %s", name)))
+	}
+
+	if labelled, ok := appsv1alpha1.ManifestResource(manifest); ok && labelled != resource {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "labels"), manifest.Labels,
+			fmt.Sprintf("the labels must match the template %s %s/%s", resource.APIVersion+"/"+resource.Kind, resource.Namespace, resource.Name)))
+	}
+
+	if _, err := appsv1alpha1.ManifestApplyWave(manifest, resource); err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "annotations").Key(constants.ManifestApplyWaveAnnotation),
+			manifest.Annotations[constants.ManifestApplyWaveAnnotation], err.Error()))
+	}
+
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(appsv1alpha1.Kind("Manifest"), manifest.Name, errs)
+	}
+	return nil
+}
+
+// templateResource returns the resource of template, the namespace of Manifest is used if it is not set in template,
+// except for the cluster scope resources in mcp-system
+func templateResource(manifest *appsv1alpha1.Manifest) (corev1.ObjectReference, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(manifest.Template.Raw); err != nil {
+		return corev1.ObjectReference{}, err
+	}
+	if obj.GetName() == "" {
+		return corev1.ObjectReference{}, fmt.Errorf("metadata.name is required")
+	}
+
+	resource := corev1.ObjectReference{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+	if resource.Namespace == "" && manifest.Namespace != constants.SystemNamespace {
+		resource.Namespace = manifest.Namespace
+	}
+	return resource, nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrapper
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/multi-cluster-platform/mcp/pkg/apis/gateway"
+)
+
+// ClusterConfig returns a copy of the hub config, whose requests are sent to the member cluster through
+// the gateway: http://localhost/apis/gateway.mcp.io/v1/clusters/{name}/api/v1/nodes. Unlike the wrapper
+// transport, the cluster is part of the host, so the discovery, RESTMapper and any clients built from the
+// config always reach the same cluster. The hub config should not be wrapped by the wrapper transport.
+func ClusterConfig(config *restclient.Config, clusterName string) *restclient.Config {
+	clusterConfig := restclient.CopyConfig(config)
+	clusterConfig.Host = strings.TrimSuffix(config.Host, pathSeparator) +
+		strings.Join([]string{pathPrefix, gateway.GroupName, version, pathCluster, clusterName}, pathSeparator)
+	return clusterConfig
+}
+
+// NewClusterClient returns a controller-runtime client of the member cluster, the RESTMapper of
+// options is replaced by the one discovering the member cluster, as the resources differ between clusters
+func NewClusterClient(config *restclient.Config, clusterName string, options client.Options) (client.Client, error) {
+	clusterConfig := ClusterConfig(config, clusterName)
+
+	mapper, err := apiutil.NewDynamicRESTMapper(clusterConfig, apiutil.WithLazyDiscovery)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create RESTMapper for cluster %s: %v", clusterName, err)
+	}
+	options.Mapper = mapper
+
+	return client.New(clusterConfig, options)
+}
+
+// ClusterClientFactory creates the controller-runtime clients of member clusters, the client of each
+// cluster is created once and reused, so that the discovery of RESTMapper is shared by the callers
+type ClusterClientFactory struct {
+	config  *restclient.Config
+	options client.Options
+
+	lock    sync.Mutex
+	clients map[string]client.Client
+}
+
+// NewClusterClientFactory returns a factory creating the clients from the hub config and options
+func NewClusterClientFactory(config *restclient.Config, options client.Options) *ClusterClientFactory {
+	return &ClusterClientFactory{
+		config:  config,
+		options: options,
+		clients: map[string]client.Client{},
+	}
+}
+
+// Client returns the client of the member cluster
+func (f *ClusterClientFactory) Client(clusterName string) (client.Client, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if c, exist := f.clients[clusterName]; exist {
+		return c, nil
+	}
+
+	c, err := NewClusterClient(f.config, clusterName, f.options)
+	if err != nil {
+		return nil, err
+	}
+	f.clients[clusterName] = c
+	return c, nil
+}
+
+// Forget drops the client of the member cluster, e.g. when the cluster is removed or re-registered
+func (f *ClusterClientFactory) Forget(clusterName string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.clients, clusterName)
+}
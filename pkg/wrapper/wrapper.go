@@ -34,22 +34,36 @@ const (
 	pathShadow  = "shadow"
 )
 
-// mcpTransport is a transport for gateway cluster and gateway shadow
+// mcpTransport is a transport for gateway cluster and gateway shadow, the requests are routed by
+// the request context or the pinned cluster, the transport itself is never changed by requests
 type mcpTransport struct {
 	delegate http.RoundTripper
 
-	clusterName string
+	// pinnedCluster is the cluster of all the requests, the cluster in request context is used if empty
+	pinnedCluster string
 }
 
 var _ http.RoundTripper = &mcpTransport{}
 
+// NewTransport returns a transport routing each request by its context, the request with WithDynamicClusterContext
+// is sent to the cluster, the one with WithDirectContext is sent to the hub cluster directly, and others fall back
+// to the gateway shadow
 func NewTransport() *mcpTransport {
 	return &mcpTransport{}
 }
 
+// NewClusterTransport returns a transport sending all the requests to the cluster, except the ones with WithDirectContext
+func NewClusterTransport(clusterName string) *mcpTransport {
+	return &mcpTransport{pinnedCluster: clusterName}
+}
+
+// NewRoundTrip wraps the delegate, it returns a new round tripper every time, so the transport could be
+// used to wrap multiple configs
 func (t *mcpTransport) NewRoundTrip(delegate http.RoundTripper) http.RoundTripper {
-	t.delegate = delegate
-	return t
+	return &mcpTransport{
+		delegate:      delegate,
+		pinnedCluster: t.pinnedCluster,
+	}
 }
 
 func (t *mcpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -57,28 +71,29 @@ func (t *mcpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		return t.delegate.RoundTrip(req)
 	}
 
-	if !t.isFallBack() {
-		clusterName, exists := getClusterContext(req.Context())
-		if !exists {
-			return nil, fmt.Errorf("missing cluster name in the request context")
+	clusterName, exists := getClusterContext(req.Context())
+	if t.pinnedCluster != "" {
+		if exists && clusterName != t.pinnedCluster {
+			return nil, fmt.Errorf("cluster %s in the request context mismatches the pinned cluster %s", clusterName, t.pinnedCluster)
 		}
-		t.clusterName = clusterName
+		clusterName = t.pinnedCluster
 	}
 
-	req.URL.Path = t.formatURL(req.URL.Path)
+	// the request must not be modified by round trippers
+	req = req.Clone(req.Context())
+	req.URL.Path = formatURL(req.URL.Path, clusterName)
+	if req.URL.RawPath != "" {
+		req.URL.RawPath = formatURL(req.URL.RawPath, clusterName)
+	}
 	return t.delegate.RoundTrip(req)
 }
 
 // shadow request, send req to hub cluster: http://localhost/apis/gateway.mcp.io/v1/shadow/api/v1/nodes
 // cluster request, send req to spoke cluster: http://localhost/apis/gateway.mcp.io/v1/clusters/{name}/api/v1/nodes
-func (t *mcpTransport) formatURL(reqPath string) string {
+func formatURL(reqPath, clusterName string) string {
 	originalPath := strings.TrimPrefix(reqPath, "/")
-	if t.isFallBack() {
+	if clusterName == "" {
 		return strings.Join([]string{pathPrefix, gateway.GroupName, version, pathShadow, originalPath}, pathSeparator)
 	}
-	return strings.Join([]string{pathPrefix, gateway.GroupName, version, pathCluster, t.clusterName, originalPath}, "/")
-}
-
-func (t *mcpTransport) isFallBack() bool {
-	return t.clusterName == ""
+	return strings.Join([]string{pathPrefix, gateway.GroupName, version, pathCluster, clusterName, originalPath}, pathSeparator)
 }
@@ -0,0 +1,275 @@
+/*
+Copyright 2022 The MultiClusterPlatform Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wrapper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper, so the tests respond without sending requests
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// echoPath responds the requested path as body
+var echoPath = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(req.URL.Path)),
+		Request:    req,
+	}, nil
+})
+
+// roundTrip sends a request of the path through the transport and returns the response body, it is called
+// from goroutines so the failures are returned instead of failing the test
+func roundTrip(transport http.RoundTripper, ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost"+path, nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to create request: %v", err)
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if req.URL.Path != path {
+		return "", fmt.Errorf("request is modified, expect path %s, got %s", path, req.URL.Path)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read response: %v", err)
+	}
+	return string(body), nil
+}
+
+func TestTransportRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport *mcpTransport
+		ctx       context.Context
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:      "dynamic cluster",
+			transport: NewTransport(),
+			ctx:       WithDynamicClusterContext(context.TODO(), "cluster1"),
+			expected:  "/apis/gateway.mcp.io/v1/clusters/cluster1/api/v1/pods",
+		},
+		{
+			name:      "fallback to shadow",
+			transport: NewTransport(),
+			ctx:       context.TODO(),
+			expected:  "/apis/gateway.mcp.io/v1/shadow/api/v1/pods",
+		},
+		{
+			name:      "direct",
+			transport: NewTransport(),
+			ctx:       WithDirectContext(WithDynamicClusterContext(context.TODO(), "cluster1")),
+			expected:  "/api/v1/pods",
+		},
+		{
+			name:      "pinned cluster",
+			transport: NewClusterTransport("cluster1"),
+			ctx:       context.TODO(),
+			expected:  "/apis/gateway.mcp.io/v1/clusters/cluster1/api/v1/pods",
+		},
+		{
+			name:      "pinned cluster with the same dynamic cluster",
+			transport: NewClusterTransport("cluster1"),
+			ctx:       WithDynamicClusterContext(context.TODO(), "cluster1"),
+			expected:  "/apis/gateway.mcp.io/v1/clusters/cluster1/api/v1/pods",
+		},
+		{
+			name:      "pinned cluster with another dynamic cluster",
+			transport: NewClusterTransport("cluster1"),
+			ctx:       WithDynamicClusterContext(context.TODO(), "cluster2"),
+			expectErr: true,
+		},
+		{
+			name:      "pinned cluster with direct",
+			transport: NewClusterTransport("cluster1"),
+			ctx:       WithDirectContext(context.TODO()),
+			expected:  "/api/v1/pods",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := roundTrip(test.transport.NewRoundTrip(echoPath), test.ctx, "/api/v1/pods")
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expect error, got path %s", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if path != test.expected {
+				t.Errorf("expect path %s, got %s", test.expected, path)
+			}
+		})
+	}
+}
+
+func TestTransportFallbackAfterClusterRequests(t *testing.T) {
+	transport := NewTransport().NewRoundTrip(echoPath)
+
+	if _, err := roundTrip(transport, WithDynamicClusterContext(context.TODO(), "cluster1"), "/api/v1/pods"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path, err := roundTrip(transport, context.TODO(), "/api/v1/pods")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "/apis/gateway.mcp.io/v1/shadow/api/v1/pods"; path != expected {
+		t.Errorf("expect path %s, got %s", expected, path)
+	}
+}
+
+func TestTransportNewRoundTripIsolated(t *testing.T) {
+	transport := NewTransport()
+	first := transport.NewRoundTrip(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("first delegate")
+	}))
+	second := transport.NewRoundTrip(echoPath)
+
+	if _, err := roundTrip(first, context.TODO(), "/api/v1/pods"); err == nil {
+		t.Errorf("expect the request sent by the first delegate")
+	}
+	if _, err := roundTrip(second, context.TODO(), "/api/v1/pods"); err != nil {
+		t.Errorf("expect the request sent by the second delegate, got %v", err)
+	}
+}
+
+// TestTransportConcurrent sends the requests of many clusters through a shared transport,
+// run with -race to detect the data races
+func TestTransportConcurrent(t *testing.T) {
+	const (
+		clusters = 20
+		requests = 50
+	)
+
+	transport := NewTransport().NewRoundTrip(echoPath)
+	pinned := NewClusterTransport("pinned").NewRoundTrip(echoPath)
+
+	var wg sync.WaitGroup
+	for i := 0; i < clusters; i++ {
+		clusterName := fmt.Sprintf("cluster%d", i)
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				path, err := roundTrip(transport, WithDynamicClusterContext(context.TODO(), clusterName), "/api/v1/pods")
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if expected := "/apis/gateway.mcp.io/v1/clusters/" + clusterName + "/api/v1/pods"; path != expected {
+					t.Errorf("expect path %s, got %s", expected, path)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				path, err := roundTrip(transport, context.TODO(), "/api/v1/pods")
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if expected := "/apis/gateway.mcp.io/v1/shadow/api/v1/pods"; path != expected {
+					t.Errorf("expect path %s, got %s", expected, path)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				path, err := roundTrip(pinned, context.TODO(), "/api/v1/pods")
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if expected := "/apis/gateway.mcp.io/v1/clusters/pinned/api/v1/pods"; path != expected {
+					t.Errorf("expect path %s, got %s", expected, path)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestClientsetConcurrent lists with a clientset wrapped by the transport from many goroutines
+func TestClientsetConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		// echo the requested path as the namespace name, so the caller checks where its request went
+		resp.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(resp, `{"kind":"NamespaceList","apiVersion":"v1","items":[{"metadata":{"name":%q}}]}`, req.URL.Path)
+	}))
+	defer server.Close()
+
+	// the client side rate limiting is not the concern here
+	config := &restclient.Config{Host: server.URL, QPS: 1000, Burst: 1000}
+	config.Wrap(NewTransport().NewRoundTrip)
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		t.Fatalf("unable to create clientset: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		clusterName := fmt.Sprintf("cluster%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				namespaces, err := client.CoreV1().Namespaces().List(WithDynamicClusterContext(context.TODO(), clusterName), metav1.ListOptions{})
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				expected := "/apis/gateway.mcp.io/v1/clusters/" + clusterName + "/api/v1/namespaces"
+				if len(namespaces.Items) != 1 || namespaces.Items[0].Name != expected {
+					t.Errorf("expect path %s, got %v", expected, namespaces.Items)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}